@@ -0,0 +1,115 @@
+package chunker
+
+import (
+	"errors"
+	"fmt"
+	"mcp-go-server/domain"
+	"sync"
+)
+
+// Chunking strategies
+const (
+	StrategyAuto  = "auto"  // Language-aware chunking where available, byte windows otherwise
+	StrategyLines = "lines" // Sliding windows measured in lines
+	StrategyBytes = "bytes" // Sliding windows measured in bytes, cut on line boundaries
+)
+
+// Default window sizes and overlaps per unit
+const (
+	DefaultByteSize    = 1000
+	DefaultByteOverlap = 200
+	DefaultLineSize    = 40
+	DefaultLineOverlap = 5
+)
+
+// Upper bounds keep every chunk well within the embedding model's input limit
+const (
+	MaxByteSize = 20000
+	MaxLineSize = 500
+)
+
+// Chunker splits file content into chunks that are embedded individually
+type Chunker interface {
+	Chunk(content string) ([]domain.Chunk, error)
+}
+
+// Factory creates a language-aware chunker for the given options
+type Factory func(opts Options) Chunker
+
+// Options configures how files are chunked. Size and Overlap are measured in
+// lines for StrategyLines and in bytes otherwise; zero values use defaults.
+type Options struct {
+	Strategy string
+	Size     int
+	Overlap  int
+}
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{
+		"Go": NewGoChunker,
+	}
+)
+
+// Register makes a language-aware chunker available for a language as
+// returned by helper.GetLanguageFromExtension
+func Register(language string, factory Factory) {
+	factoriesMu.Lock()
+	factories[language] = factory
+	factoriesMu.Unlock()
+}
+
+// For returns the chunker to use for files of a language
+func For(language string, opts Options) Chunker {
+	opts = opts.WithDefaults()
+	if opts.Strategy == StrategyAuto {
+		factoriesMu.RLock()
+		factory, exists := factories[language]
+		factoriesMu.RUnlock()
+		if exists {
+			return factory(opts)
+		}
+	}
+	return NewWindowChunker(opts)
+}
+
+// WithDefaults fills in the default strategy, size and overlap
+func (opts Options) WithDefaults() Options {
+	if opts.Strategy == "" {
+		opts.Strategy = StrategyAuto
+	}
+	if opts.Size <= 0 {
+		opts.Size = DefaultByteSize
+		opts.Overlap = DefaultByteOverlap
+		if opts.Strategy == StrategyLines {
+			opts.Size = DefaultLineSize
+			opts.Overlap = DefaultLineOverlap
+		}
+	}
+	if opts.Overlap < 0 {
+		opts.Overlap = 0
+	}
+	return opts
+}
+
+// Validate checks the strategy and that the overlap is smaller than the size
+func (opts Options) Validate() error {
+	opts = opts.WithDefaults()
+
+	maxSize := MaxByteSize
+	switch opts.Strategy {
+	case StrategyAuto, StrategyBytes:
+	case StrategyLines:
+		maxSize = MaxLineSize
+	default:
+		return fmt.Errorf("unknown chunk strategy: %s", opts.Strategy)
+	}
+
+	if opts.Size > maxSize {
+		return fmt.Errorf("chunk size must not exceed %d for the %s strategy", maxSize, opts.Strategy)
+	}
+	if opts.Overlap >= opts.Size {
+		return errors.New("chunk overlap must be smaller than the chunk size")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package chunker
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"mcp-go-server/domain"
+	"strings"
+)
+
+// GoChunker splits Go source into one chunk per top-level declaration. Doc
+// comments stay attached to their declaration, as do comments and blank
+// lines preceding it; the package clause and imports form the first chunk.
+// Declarations larger than the byte size are split into windows. Chunk
+// returns an error when content does not parse as Go.
+type GoChunker struct {
+	opts Options
+}
+
+// NewGoChunker creates a Go declaration chunker
+func NewGoChunker(opts Options) Chunker {
+	return &GoChunker{opts: opts.WithDefaults()}
+}
+
+// Chunk implements Chunker
+func (c *GoChunker) Chunk(content string) ([]domain.Chunk, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(content, "\n")
+	packageName := file.Name.Name
+	chunks := []domain.Chunk{}
+	nextLine := 1
+
+	// addRange adds the lines up to and including endLine as one or more chunks
+	addRange := func(endLine int, receiver, symbol string) {
+		startLine := nextLine
+		nextLine = endLine + 1
+
+		// Leading blank lines carry no meaning
+		for startLine <= endLine && strings.TrimSpace(lines[startLine-1]) == "" {
+			startLine++
+		}
+		if startLine > endLine {
+			return
+		}
+
+		text := strings.Join(lines[startLine-1:endLine], "\n")
+		pieces := []domain.Chunk{{Content: text, StartLine: startLine, EndLine: endLine}}
+		if len(text) > c.opts.Size {
+			pieces = splitWindows(lines[startLine-1:endLine], startLine, c.opts)
+		}
+
+		for _, piece := range pieces {
+			piece.Package = packageName
+			piece.Receiver = receiver
+			piece.Symbol = symbol
+			chunks = append(chunks, piece)
+		}
+	}
+
+	// The package clause, file comments and imports come first
+	headerEnd := fset.Position(file.Name.End()).Line
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			headerEnd = fset.Position(genDecl.End()).Line
+		}
+	}
+	addRange(headerEnd, "", "")
+
+	for _, decl := range file.Decls {
+		endLine := fset.Position(decl.End()).Line
+		if endLine < nextLine {
+			continue // Imports are part of the header
+		}
+
+		receiver, symbol := declSymbol(decl)
+		addRange(endLine, receiver, symbol)
+	}
+
+	// Keep comments following the last declaration
+	if nextLine <= len(lines) {
+		addRange(len(lines), "", "")
+	}
+
+	return chunks, nil
+}
+
+// declSymbol returns the receiver type and the declared names of a
+// top-level declaration
+func declSymbol(decl ast.Decl) (string, string) {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		receiver := ""
+		if decl.Recv != nil && len(decl.Recv.List) > 0 {
+			receiver = types.ExprString(decl.Recv.List[0].Type)
+		}
+		return receiver, decl.Name.Name
+	case *ast.GenDecl:
+		var names []string
+		for _, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, spec.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range spec.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+		return "", strings.Join(names, ", ")
+	default:
+		return "", ""
+	}
+}
@@ -0,0 +1,116 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+)
+
+const goSource = `// Package shapes draws shapes.
+package shapes
+
+import (
+	"fmt"
+	"math"
+)
+
+// Pi is rounded
+const Pi = 3.14
+
+var (
+	width, height = 1, 2
+)
+
+// Circle is round
+type Circle struct {
+	R float64
+}
+
+// Area returns the area
+func (c *Circle) Area() float64 {
+	return math.Pi * c.R * c.R
+}
+
+func describe(c Circle) string {
+	return fmt.Sprint(c.R)
+}
+
+// trailing comment
+`
+
+func TestGoChunker(t *testing.T) {
+	chunks, err := NewGoChunker(Options{}).Chunk(goSource)
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+
+	want := []struct {
+		startLine, endLine int
+		receiver, symbol   string
+		firstLine          string
+	}{
+		{1, 7, "", "", "// Package shapes draws shapes."},
+		{9, 10, "", "Pi", "// Pi is rounded"},
+		{12, 14, "", "width, height", "var ("},
+		{16, 19, "", "Circle", "// Circle is round"},
+		{21, 24, "*Circle", "Area", "// Area returns the area"},
+		{26, 28, "", "describe", "func describe(c Circle) string {"},
+		{30, 31, "", "", "// trailing comment"},
+	}
+	if len(chunks) != len(want) {
+		for _, chunk := range chunks {
+			t.Logf("%d-%d %q", chunk.StartLine, chunk.EndLine, chunk.Symbol)
+		}
+		t.Fatalf("Chunk() returned %d chunks, want %d", len(chunks), len(want))
+	}
+
+	for i, chunk := range chunks {
+		w := want[i]
+		if chunk.StartLine != w.startLine || chunk.EndLine != w.endLine {
+			t.Errorf("chunk %d lines = %d-%d, want %d-%d", i, chunk.StartLine, chunk.EndLine, w.startLine, w.endLine)
+		}
+		if chunk.Receiver != w.receiver || chunk.Symbol != w.symbol {
+			t.Errorf("chunk %d receiver, symbol = %q, %q, want %q, %q", i, chunk.Receiver, chunk.Symbol, w.receiver, w.symbol)
+		}
+		if chunk.Package != "shapes" {
+			t.Errorf("chunk %d package = %q, want shapes", i, chunk.Package)
+		}
+		if first := strings.SplitN(chunk.Content, "\n", 2)[0]; first != w.firstLine {
+			t.Errorf("chunk %d starts with %q, want %q", i, first, w.firstLine)
+		}
+	}
+}
+
+func TestGoChunkerSplitsLargeDeclarations(t *testing.T) {
+	var body strings.Builder
+	body.WriteString("package big\n\nfunc Big() {\n")
+	for i := 0; i < 100; i++ {
+		body.WriteString("\tprintln(\"a fairly long line of code to fill the window\")\n")
+	}
+	body.WriteString("}\n")
+
+	chunks, err := NewGoChunker(Options{Size: 500, Overlap: 100}).Chunk(body.String())
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+	if len(chunks) < 3 {
+		t.Fatalf("Chunk() returned %d chunks, want the function split into windows", len(chunks))
+	}
+
+	for _, chunk := range chunks[1:] {
+		if chunk.Symbol != "Big" || chunk.Package != "big" {
+			t.Errorf("window %d-%d symbol, package = %q, %q, want Big, big", chunk.StartLine, chunk.EndLine, chunk.Symbol, chunk.Package)
+		}
+		if len(chunk.Content) > 500 {
+			t.Errorf("window %d-%d holds %d bytes, want at most 500", chunk.StartLine, chunk.EndLine, len(chunk.Content))
+		}
+	}
+	if last := chunks[len(chunks)-1]; last.EndLine != 104 {
+		t.Errorf("last window ends at line %d, want 104", last.EndLine)
+	}
+}
+
+func TestGoChunkerRejectsInvalidSource(t *testing.T) {
+	if _, err := NewGoChunker(Options{}).Chunk("package broken\n\nfunc {"); err == nil {
+		t.Error("Chunk() accepted source that does not parse")
+	}
+}
@@ -0,0 +1,98 @@
+package chunker
+
+import (
+	"mcp-go-server/domain"
+	"strings"
+)
+
+// WindowChunker cuts content into overlapping windows of whole lines. With
+// StrategyLines a window holds Size lines; otherwise it holds as many lines
+// as fit in Size bytes. Consecutive windows share up to Overlap lines or
+// bytes so context at a boundary appears in both chunks.
+type WindowChunker struct {
+	opts Options
+}
+
+// NewWindowChunker creates a sliding window chunker
+func NewWindowChunker(opts Options) Chunker {
+	return &WindowChunker{opts: opts.WithDefaults()}
+}
+
+// Chunk implements Chunker
+func (c *WindowChunker) Chunk(content string) ([]domain.Chunk, error) {
+	return splitWindows(strings.Split(content, "\n"), 1, c.opts), nil
+}
+
+// splitWindows splits lines, the first of which has number firstLine, into
+// windows. Windows consisting only of blank lines are dropped.
+func splitWindows(lines []string, firstLine int, opts Options) []domain.Chunk {
+	opts = opts.WithDefaults()
+	chunks := []domain.Chunk{}
+
+	for start := 0; start < len(lines); {
+		end := windowEnd(lines, start, opts)
+
+		text := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(text) != "" {
+			chunks = append(chunks, domain.Chunk{
+				Content:   text,
+				StartLine: firstLine + start,
+				EndLine:   firstLine + end - 1,
+			})
+		}
+		if end >= len(lines) {
+			break
+		}
+
+		// Always advance by at least one line
+		next := windowOverlapStart(lines, start, end, opts)
+		if next <= start {
+			next = start + 1
+		}
+		start = next
+	}
+
+	return chunks
+}
+
+// windowEnd returns the exclusive end of the window starting at start. A
+// window always holds at least one line, even one longer than Size bytes.
+func windowEnd(lines []string, start int, opts Options) int {
+	if opts.Strategy == StrategyLines {
+		end := start + opts.Size
+		if end > len(lines) {
+			end = len(lines)
+		}
+		return end
+	}
+
+	end, size := start, 0
+	for end < len(lines) {
+		lineSize := len(lines[end]) + 1 // +1 for newline
+		if size > 0 && size+lineSize > opts.Size {
+			break
+		}
+		size += lineSize
+		end++
+	}
+	return end
+}
+
+// windowOverlapStart returns where the window after [start, end) begins so
+// that the two share at most Overlap lines or bytes
+func windowOverlapStart(lines []string, start, end int, opts Options) int {
+	if opts.Strategy == StrategyLines {
+		return end - opts.Overlap
+	}
+
+	next, size := end, 0
+	for next > start {
+		lineSize := len(lines[next-1]) + 1
+		if size+lineSize > opts.Overlap {
+			break
+		}
+		size += lineSize
+		next--
+	}
+	return next
+}
@@ -0,0 +1,138 @@
+package chunker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns n lines "line 1" to "line n"
+func numberedLines(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestWindowChunker(t *testing.T) {
+	type window struct{ start, end int }
+
+	tests := []struct {
+		name    string
+		opts    Options
+		content string
+		want    []window
+	}{
+		{
+			name:    "line windows with overlap",
+			opts:    Options{Strategy: StrategyLines, Size: 4, Overlap: 1},
+			content: numberedLines(10),
+			want:    []window{{1, 4}, {4, 7}, {7, 10}},
+		},
+		{
+			name:    "line windows without overlap",
+			opts:    Options{Strategy: StrategyLines, Size: 5},
+			content: numberedLines(10),
+			want:    []window{{1, 5}, {6, 10}},
+		},
+		{
+			name: "byte windows cut on line boundaries",
+			// Every line is 7 bytes including its newline
+			opts:    Options{Strategy: StrategyBytes, Size: 21, Overlap: 7},
+			content: numberedLines(7),
+			want:    []window{{1, 3}, {3, 5}, {5, 7}},
+		},
+		{
+			name:    "line longer than the window",
+			opts:    Options{Strategy: StrategyBytes, Size: 10, Overlap: 5},
+			content: "short\n" + strings.Repeat("x", 50) + "\nshort",
+			want:    []window{{1, 1}, {2, 2}, {3, 3}},
+		},
+		{
+			name:    "overlap as large as the window still advances",
+			opts:    Options{Strategy: StrategyLines, Size: 2, Overlap: 2},
+			content: numberedLines(3),
+			want:    []window{{1, 2}, {2, 3}},
+		},
+		{
+			name:    "blank windows are dropped",
+			opts:    Options{Strategy: StrategyLines, Size: 2},
+			content: "a\nb\n\n\n\nc",
+			want:    []window{{1, 2}, {5, 6}},
+		},
+		{
+			name:    "empty content",
+			opts:    Options{Strategy: StrategyLines, Size: 2},
+			content: "",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := NewWindowChunker(tt.opts).Chunk(tt.content)
+			if err != nil {
+				t.Fatalf("Chunk() error = %v", err)
+			}
+
+			var got []window
+			lines := strings.Split(tt.content, "\n")
+			for _, chunk := range chunks {
+				got = append(got, window{chunk.StartLine, chunk.EndLine})
+				if want := strings.Join(lines[chunk.StartLine-1:chunk.EndLine], "\n"); chunk.Content != want {
+					t.Errorf("window %d-%d content = %q, want %q", chunk.StartLine, chunk.EndLine, chunk.Content, want)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Chunk() windows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "defaults", opts: Options{}},
+		{name: "line defaults", opts: Options{Strategy: StrategyLines}},
+		{name: "unknown strategy", opts: Options{Strategy: "words"}, wantErr: true},
+		{name: "overlap equals size", opts: Options{Size: 100, Overlap: 100}, wantErr: true},
+		{name: "byte size over limit", opts: Options{Strategy: StrategyBytes, Size: MaxByteSize + 1}, wantErr: true},
+		{name: "line size over limit", opts: Options{Strategy: StrategyLines, Size: MaxLineSize + 1}, wantErr: true},
+		{name: "negative overlap", opts: Options{Size: 100, Overlap: -5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		opts     Options
+		wantGo   bool
+	}{
+		{name: "go with auto strategy", language: "Go", wantGo: true},
+		{name: "go with line windows", language: "Go", opts: Options{Strategy: StrategyLines}},
+		{name: "other language", language: "Python"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, isGo := For(tt.language, tt.opts).(*GoChunker)
+			if isGo != tt.wantGo {
+				t.Errorf("For(%q) returned a Go chunker: %v, want %v", tt.language, isGo, tt.wantGo)
+			}
+		})
+	}
+}
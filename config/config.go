@@ -3,8 +3,14 @@ package config
 import (
 	"errors"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
+// MaxIndexConcurrency caps the number of files indexed in parallel
+const MaxIndexConcurrency = 32
+
 type Config struct {
 	Port                   string
 	PineconeAPIKey         string
@@ -15,6 +21,16 @@ type Config struct {
 	GitHubClientSecret     string
 	GitHubOAuthRedirectURL string
 	JWTSecret              string
+	MCPSecretToken         string
+	MCPSecretTokenHashes   []string
+	MCPServiceUserID       string
+	MCPAllowedOrigins      []string
+	SummaryMode            string
+	IndexConcurrency       int
+	RepoCacheDir           string
+	RepoCacheMaxBytes      int64
+	CatalogDir             string
+	GitProviderHosts       map[string]string
 }
 
 func LoadConfig() (*Config, error) {
@@ -28,6 +44,40 @@ func LoadConfig() (*Config, error) {
 		GitHubClientSecret:     getEnv("GITHUB_CLIENT_SECRET", ""),
 		GitHubOAuthRedirectURL: getEnv("GITHUB_OAUTH_REDIRECT_URL", "http://localhost:8081/auth/github/callback"),
 		JWTSecret:              getEnv("JWT_SECRET", "mcp-secret-key"),
+		MCPSecretToken:         getEnv("MCP_SECRET_TOKEN", ""),
+		MCPSecretTokenHashes:   getEnvList("MCP_SECRET_TOKEN_HASHES"),
+		MCPServiceUserID:       getEnv("MCP_SERVICE_USER_ID", "mcp-service"),
+		MCPAllowedOrigins:      getEnvList("MCP_ALLOWED_ORIGINS"),
+		SummaryMode:            getEnv("SUMMARY_MODE", "server"),
+	}
+
+	indexConcurrency, err := strconv.Atoi(getEnv("INDEX_CONCURRENCY", "4"))
+	if err != nil || indexConcurrency < 1 || indexConcurrency > MaxIndexConcurrency {
+		return nil, errors.New("INDEX_CONCURRENCY must be a number between 1 and " + strconv.Itoa(MaxIndexConcurrency))
+	}
+	cfg.IndexConcurrency = indexConcurrency
+
+	// Bare mirrors of cloned repositories; the cache is off unless a size
+	// is configured
+	cfg.RepoCacheDir = getEnv("REPO_CACHE_DIR", filepath.Join(os.TempDir(), "mcp-repo-cache"))
+	repoCacheMaxMB, err := strconv.ParseInt(getEnv("REPO_CACHE_MAX_MB", "0"), 10, 64)
+	if err != nil || repoCacheMaxMB < 0 {
+		return nil, errors.New("REPO_CACHE_MAX_MB must be a non-negative number of megabytes")
+	}
+	cfg.RepoCacheMaxBytes = repoCacheMaxMB * 1024 * 1024
+
+	// Indexed files, commits, owners and readers survive restarts here
+	cfg.CatalogDir = getEnv("CATALOG_DIR", filepath.Join("data", "catalog"))
+
+	// Self-hosted git servers as host=provider pairs, e.g. git.example.com=gitlab
+	cfg.GitProviderHosts = make(map[string]string)
+	for _, entry := range getEnvList("GIT_PROVIDER_HOSTS") {
+		host, provider, ok := strings.Cut(entry, "=")
+		host, provider = strings.TrimSpace(host), strings.TrimSpace(provider)
+		if !ok || host == "" || provider == "" {
+			return nil, errors.New("GIT_PROVIDER_HOSTS must contain host=provider pairs")
+		}
+		cfg.GitProviderHosts[host] = provider
 	}
 
 	// Validate required fields with helpful error messages
@@ -38,6 +88,18 @@ func LoadConfig() (*Config, error) {
 		return nil, errors.New("OPENAI_API_KEY is required. Please set it in your environment variables or .env file")
 	}
 
+	if cfg.SummaryMode != "server" && cfg.SummaryMode != "client" {
+		return nil, errors.New("SUMMARY_MODE must be either \"server\" or \"client\"")
+	}
+	if cfg.MCPSecretToken != "" && len(cfg.MCPSecretToken) < 16 {
+		return nil, errors.New("MCP_SECRET_TOKEN must be at least 16 characters long")
+	}
+	for _, hash := range cfg.MCPSecretTokenHashes {
+		if len(hash) != 64 {
+			return nil, errors.New("MCP_SECRET_TOKEN_HASHES must contain hex-encoded SHA-256 digests")
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -47,3 +109,14 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList reads a comma-separated environment variable
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, strings.ToLower(value))
+		}
+	}
+	return values
+}
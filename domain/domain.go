@@ -28,9 +28,24 @@ type CodeChunk struct {
 	Repository string    `json:"repository"`
 	Branch     string    `json:"branch"`
 	Language   string    `json:"language"`
+	StartLine  int       `json:"start_line"`
+	EndLine    int       `json:"end_line"`
+	CommitSHA  string    `json:"commit_sha"`
+	Private    bool      `json:"private"`
 	Embedding  []float32 `json:"embedding"`
 }
 
+// Chunk is a piece of a file that is embedded as a single vector. Lines are
+// 1-based and inclusive.
+type Chunk struct {
+	Content   string `json:"content"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Package   string `json:"package,omitempty"`  // Go package name
+	Receiver  string `json:"receiver,omitempty"` // Go method receiver type
+	Symbol    string `json:"symbol,omitempty"`   // Declared function, type, const or var names
+}
+
 // SearchResult represents a search result with score
 type SearchResult struct {
 	CodeChunk
@@ -46,4 +61,25 @@ type Repository struct {
 	IndexedAt  string `json:"indexed_at"`
 	FileCount  int    `json:"file_count"`
 	ChunkCount int    `json:"chunk_count"`
-}
\ No newline at end of file
+	CommitSHA  string `json:"commit_sha"`
+	Private    bool   `json:"private"`
+}
+
+// IndexedFile represents a file whose chunks are stored in the vector database
+type IndexedFile struct {
+	Repository string `json:"repository"`
+	Branch     string `json:"branch"`
+	Path       string `json:"path"`
+	Language   string `json:"language"`
+	Content    string `json:"content"`
+	Size       int    `json:"size"`
+	ChunkCount int    `json:"chunk_count"`
+	IndexedAt  string `json:"indexed_at"`
+	Private    bool   `json:"private"` // Cloned with a user's token; only readable by its readers
+}
+
+// FileChanges lists the paths that changed between two indexed commits
+type FileChanges struct {
+	Changed []string `json:"changed"` // Added, modified or renamed-to paths
+	Removed []string `json:"removed"` // Deleted or renamed-from paths
+}
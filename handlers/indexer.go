@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"mcp-go-server/models"
 	"mcp-go-server/response"
@@ -11,8 +12,15 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
-// IndexRepository indexes a GitHub repository
+// IndexRepository starts indexing a git repository in the background
 func IndexRepository(c *gin.Context) {
+	userID, exists := c.Get(models.UserIDKey)
+	if !exists {
+		errRes := response.ErrorClientResponse(http.StatusUnauthorized, "User not authenticated", nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
 	var indexReq models.IndexRequest
 
 	if err := c.ShouldBindJSON(&indexReq); err != nil {
@@ -28,32 +36,154 @@ func IndexRepository(c *gin.Context) {
 		return
 	}
 
-	// Set default branch
-	if indexReq.Branch == "" {
-		indexReq.Branch = "main"
-	}
-
 	// Log the start of indexing process
 	log.Printf("🎯 Indexing request received for repository: %s (branch: %s)", indexReq.RepoURL, indexReq.Branch)
 	log.Printf("⏱️  This process may take 5-10 minutes depending on repository size...")
 
-	// Index repository
-	result, err := usecase.IndexRepository(indexReq)
+	// Start indexing job
+	job, err := usecase.StartIndexJob(userID.(string), indexReq)
 	if err != nil {
-		if result.Status == "empty" {
-			log.Printf("⚠️  Repository is empty or unsupported: %s", result.Repository)
-			errRes := response.ErrorClientResponse(http.StatusUnprocessableEntity, "Repository is empty or contains no supported files for indexing.", err.Error())
-			c.JSON(http.StatusUnprocessableEntity, errRes)
+		if errors.Is(err, models.ErrRefNotFound) {
+			errRes := response.ErrorClientResponse(http.StatusNotFound, "Branch or tag not found", err.Error())
+			c.JSON(http.StatusNotFound, errRes)
+			return
+		}
+		if errors.Is(err, models.ErrIndexingInProgress) {
+			errRes := response.ErrorClientResponse(http.StatusConflict, "Repository is already being indexed", err.Error())
+			c.JSON(http.StatusConflict, errRes)
+			return
+		}
+		log.Printf("❌ Indexing request rejected: %v", err)
+		errRes := response.ErrorClientResponse(http.StatusBadRequest, "Repository indexing could not be started", err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	c.Header("Location", "/index/jobs/"+job.ID)
+	successRes := response.ClientResponse(http.StatusAccepted, "Repository indexing started", job, nil)
+	c.JSON(http.StatusAccepted, successRes)
+}
+
+// UploadRepository starts indexing a repository snapshot uploaded as a
+// .tar.gz or .zip archive in the "archive" form field
+func UploadRepository(c *gin.Context) {
+	userID, exists := c.Get(models.UserIDKey)
+	if !exists {
+		errRes := response.ErrorClientResponse(http.StatusUnauthorized, "User not authenticated", nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	// Leave room for the other form fields next to the archive
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, models.MaxUploadSize+1<<20)
+
+	var uploadReq models.UploadIndexRequest
+	if err := c.ShouldBind(&uploadReq); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			errRes := response.ErrorClientResponse(http.StatusRequestEntityTooLarge, "Archive too large", err.Error())
+			c.JSON(http.StatusRequestEntityTooLarge, errRes)
 			return
 		}
-		log.Printf("❌ Indexing failed: %v", err)
-		errRes := response.ErrorClientResponse(http.StatusInternalServerError, "Repository indexing failed", err.Error())
+		errRes := response.ErrorClientResponse(http.StatusBadRequest, "Invalid request format", err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	// Validate the request
+	if err := validator.New().Struct(uploadReq); err != nil {
+		errRes := response.ErrorClientResponse(http.StatusBadRequest, "Validation failed", err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	fileHeader, err := c.FormFile("archive")
+	if err != nil {
+		errRes := response.ErrorClientResponse(http.StatusBadRequest, "Archive file is required", err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+	if fileHeader.Size > models.MaxUploadSize {
+		errRes := response.ErrorClientResponse(http.StatusRequestEntityTooLarge, "Archive too large", nil)
+		c.JSON(http.StatusRequestEntityTooLarge, errRes)
+		return
+	}
+
+	archive, err := fileHeader.Open()
+	if err != nil {
+		errRes := response.ErrorClientResponse(http.StatusInternalServerError, "Failed to read archive", err.Error())
 		c.JSON(http.StatusInternalServerError, errRes)
 		return
 	}
+	defer archive.Close()
 
-	log.Printf("🎉 Indexing completed successfully for: %s", result.Repository)
-	successRes := response.ClientResponse(http.StatusOK, "Repository indexed successfully", result, nil)
+	log.Printf("🎯 Upload indexing request received for %s@%s (%s, %d bytes)", uploadReq.Repository, uploadReq.Ref, fileHeader.Filename, fileHeader.Size)
+
+	job, err := usecase.StartUploadIndexJob(userID.(string), uploadReq, archive, fileHeader.Size, fileHeader.Filename)
+	if err != nil {
+		if errors.Is(err, models.ErrRepositoryForbidden) {
+			errRes := response.ErrorClientResponse(http.StatusForbidden, "Repository cannot be replaced", err.Error())
+			c.JSON(http.StatusForbidden, errRes)
+			return
+		}
+		if errors.Is(err, models.ErrIndexingInProgress) {
+			errRes := response.ErrorClientResponse(http.StatusConflict, "Repository is already being indexed", err.Error())
+			c.JSON(http.StatusConflict, errRes)
+			return
+		}
+		log.Printf("❌ Upload indexing request rejected: %v", err)
+		errRes := response.ErrorClientResponse(http.StatusBadRequest, "Archive indexing could not be started", err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
+
+	c.Header("Location", "/index/jobs/"+job.ID)
+	successRes := response.ClientResponse(http.StatusAccepted, "Archive indexing started", job, nil)
+	c.JSON(http.StatusAccepted, successRes)
+}
+
+// GetIndexJob returns the live progress of an indexing job
+func GetIndexJob(c *gin.Context) {
+	userID, exists := c.Get(models.UserIDKey)
+	if !exists {
+		errRes := response.ErrorClientResponse(http.StatusUnauthorized, "User not authenticated", nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	job, err := usecase.GetIndexJob(userID.(string), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, models.ErrJobNotFound) {
+			errRes := response.ErrorClientResponse(http.StatusNotFound, "Indexing job not found", err.Error())
+			c.JSON(http.StatusNotFound, errRes)
+			return
+		}
+		errRes := response.ErrorClientResponse(http.StatusInternalServerError, "Failed to retrieve indexing job", err.Error())
+		c.JSON(http.StatusInternalServerError, errRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Indexing job retrieved successfully", job, nil)
+	c.JSON(http.StatusOK, successRes)
+}
+
+// ListIndexJobs returns the caller's indexing jobs
+func ListIndexJobs(c *gin.Context) {
+	userID, exists := c.Get(models.UserIDKey)
+	if !exists {
+		errRes := response.ErrorClientResponse(http.StatusUnauthorized, "User not authenticated", nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	jobs, err := usecase.ListIndexJobs(userID.(string))
+	if err != nil {
+		errRes := response.ErrorClientResponse(http.StatusInternalServerError, "Failed to retrieve indexing jobs", err.Error())
+		c.JSON(http.StatusInternalServerError, errRes)
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Indexing jobs retrieved successfully", jobs, nil)
 	c.JSON(http.StatusOK, successRes)
 }
 
@@ -76,3 +206,41 @@ func GetRepositories(c *gin.Context) {
 	successRes := response.ClientResponse(http.StatusOK, "Repositories retrieved successfully", repositories, nil)
 	c.JSON(http.StatusOK, successRes)
 }
+
+// DeleteRepository deletes the vectors and catalog entries of an indexed
+// repository. The optional branch query parameter limits the deletion to one
+// branch. Nested repository names such as gitlab.com/group/project are
+// matched by the trailing wildcard.
+func DeleteRepository(c *gin.Context) {
+	userID, exists := c.Get(models.UserIDKey)
+	if !exists {
+		errRes := response.ErrorClientResponse(http.StatusUnauthorized, "User not authenticated", nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
+	repositoryName := c.Param("owner") + c.Param("name") // name keeps its leading slash
+	branch := c.Query("branch")
+
+	result, err := usecase.DeleteRepository(c.Request.Context(), userID.(string), repositoryName, branch)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrRepositoryNotFound):
+			errRes := response.ErrorClientResponse(http.StatusNotFound, "Repository not found", err.Error())
+			c.JSON(http.StatusNotFound, errRes)
+		case errors.Is(err, models.ErrRepositoryForbidden):
+			errRes := response.ErrorClientResponse(http.StatusForbidden, "Repository cannot be deleted", err.Error())
+			c.JSON(http.StatusForbidden, errRes)
+		case errors.Is(err, models.ErrIndexingInProgress):
+			errRes := response.ErrorClientResponse(http.StatusConflict, "Repository is being indexed", err.Error())
+			c.JSON(http.StatusConflict, errRes)
+		default:
+			errRes := response.ErrorClientResponse(http.StatusInternalServerError, "Failed to delete repository", err.Error())
+			c.JSON(http.StatusInternalServerError, errRes)
+		}
+		return
+	}
+
+	successRes := response.ClientResponse(http.StatusOK, "Repository deleted successfully", result, nil)
+	c.JSON(http.StatusOK, successRes)
+}
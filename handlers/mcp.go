@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mcp-go-server/mcp"
+	"mcp-go-server/models"
+	"mcp-go-server/response"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MCPSessionHeader carries the MCP session ID on Streamable HTTP requests
+const MCPSessionHeader = "Mcp-Session-Id"
+
+// sseKeepAliveInterval is how often idle SSE streams receive a comment
+const sseKeepAliveInterval = 30 * time.Second
+
+// maxMCPBodySize bounds the size of a POSTed JSON-RPC payload
+const maxMCPBodySize = 10 * 1024 * 1024
+
+// MCPPost handles JSON-RPC messages sent by the client over Streamable HTTP
+func MCPPost(server *mcp.Server) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get(models.UserIDKey)
+		if !exists {
+			errRes := response.ErrorClientResponse(http.StatusUnauthorized, "User not authenticated", nil)
+			c.JSON(http.StatusUnauthorized, errRes)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxMCPBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				errRes := response.ErrorClientResponse(http.StatusRequestEntityTooLarge, "Request body too large", fmt.Sprintf("payloads are limited to %d bytes", maxMCPBodySize))
+				c.JSON(http.StatusRequestEntityTooLarge, errRes)
+				return
+			}
+			errRes := response.ErrorClientResponse(http.StatusBadRequest, "Failed to read request body", err.Error())
+			c.JSON(http.StatusBadRequest, errRes)
+			return
+		}
+
+		info, err := mcp.InspectPayload(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &mcp.Response{
+				JSONRPC: mcp.JSONRPCVersion,
+				ID:      json.RawMessage("null"),
+				Error:   &mcp.Error{Code: mcp.CodeParseError, Message: "parse error", Data: err.Error()},
+			})
+			return
+		}
+
+		// Initialize starts a new session; everything else must belong to one
+		var session *mcp.Session
+		if info.HasInitialize {
+			session = server.CreateSession(userID.(string))
+			c.Header(MCPSessionHeader, session.ID)
+			log.Printf("🆕 MCP session created: %s", session.ID)
+		} else {
+			var ok bool
+			if session, ok = lookupMCPSession(c, server); !ok {
+				return
+			}
+		}
+
+		// A dropped connection is not a cancellation; clients cancel
+		// explicitly with notifications/cancelled
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		// Notifications and client responses are acknowledged without a body
+		if !info.HasRequests {
+			server.Handle(ctx, session, body)
+			c.Status(http.StatusAccepted)
+			return
+		}
+
+		if !acceptsEventStream(c) {
+			result := server.Handle(ctx, session, body)
+			if result == nil {
+				c.Status(http.StatusAccepted)
+				return
+			}
+			c.JSON(http.StatusOK, result)
+			return
+		}
+
+		// Answer over SSE so notifications emitted while handling the
+		// requests reach the client before the responses
+		stream := newSSEStream(c)
+		result := server.Handle(mcp.WithSender(ctx, stream.send), session, body)
+		if result != nil {
+			if err := stream.send(result); err != nil {
+				log.Printf("⚠️  Failed to write MCP response: %v", err)
+			}
+		}
+		stream.close()
+	}
+}
+
+// MCPGet opens a standalone SSE stream for server-initiated messages
+func MCPGet(server *mcp.Server) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, ok := lookupMCPSession(c, server)
+		if !ok {
+			return
+		}
+
+		if !acceptsEventStream(c) {
+			errRes := response.ErrorClientResponse(http.StatusNotAcceptable, "Accept header must include text/event-stream", nil)
+			c.JSON(http.StatusNotAcceptable, errRes)
+			return
+		}
+
+		if session.HasSender() {
+			errRes := response.ErrorClientResponse(http.StatusConflict, "An SSE stream is already open for this session", nil)
+			c.JSON(http.StatusConflict, errRes)
+			return
+		}
+
+		stream := newSSEStream(c)
+		session.SetSender(stream.send)
+		defer func() {
+			session.SetSender(nil)
+			stream.close()
+		}()
+
+		log.Printf("📡 MCP SSE stream opened for session %s", session.ID)
+
+		ticker := time.NewTicker(sseKeepAliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				log.Printf("📴 MCP SSE stream closed for session %s", session.ID)
+				return
+			case <-ticker.C:
+				if err := stream.keepAlive(); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// MCPDelete terminates an MCP session
+func MCPDelete(server *mcp.Server) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, ok := lookupMCPSession(c, server)
+		if !ok {
+			return
+		}
+
+		server.CloseSession(session.ID)
+		log.Printf("🗑️  MCP session terminated: %s", session.ID)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// lookupMCPSession resolves the session named in the request headers,
+// writing an error response if it is missing
+func lookupMCPSession(c *gin.Context, server *mcp.Server) (*mcp.Session, bool) {
+	userID, exists := c.Get(models.UserIDKey)
+	if !exists {
+		errRes := response.ErrorClientResponse(http.StatusUnauthorized, "User not authenticated", nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return nil, false
+	}
+
+	sessionID := c.GetHeader(MCPSessionHeader)
+	if sessionID == "" {
+		errRes := response.ErrorClientResponse(http.StatusBadRequest, "Mcp-Session-Id header is required", nil)
+		c.JSON(http.StatusBadRequest, errRes)
+		return nil, false
+	}
+
+	session, err := server.GetSession(sessionID, userID.(string))
+	if err != nil {
+		errRes := response.ErrorClientResponse(http.StatusNotFound, "MCP session not found", err.Error())
+		c.JSON(http.StatusNotFound, errRes)
+		return nil, false
+	}
+
+	return session, true
+}
+
+func acceptsEventStream(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
+
+// sseStream writes JSON-RPC messages as server-sent events. Writes are
+// serialized and rejected once the stream has been closed.
+type sseStream struct {
+	mu      sync.Mutex
+	c       *gin.Context
+	closed  bool
+	eventID int
+}
+
+func newSSEStream(c *gin.Context) *sseStream {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	return &sseStream{c: c}
+}
+
+func (s *sseStream) send(msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return mcp.ErrNoClientStream
+	}
+
+	s.eventID++
+	if _, err := fmt.Fprintf(s.c.Writer, "id: %d\nevent: message\ndata: %s\n\n", s.eventID, data); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+func (s *sseStream) keepAlive() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return mcp.ErrNoClientStream
+	}
+
+	if _, err := io.WriteString(s.c.Writer, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+func (s *sseStream) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+}
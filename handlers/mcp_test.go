@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bytes"
+	"mcp-go-server/config"
+	"mcp-go-server/database"
+	"mcp-go-server/mcp"
+	"mcp-go-server/middleware"
+	"mcp-go-server/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMCPPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := database.DB
+	database.DB = &database.Database{Config: &config.Config{MCPAllowedOrigins: []string{"https://app.example.com"}}}
+	t.Cleanup(func() { database.DB = previous })
+
+	router := gin.New()
+	router.POST("/mcp", func(c *gin.Context) {
+		c.Set(models.UserIDKey, "alice")
+	}, middleware.MCPOriginMiddleware(), MCPPost(mcp.NewServer()))
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`
+	tests := []struct {
+		name       string
+		origin     string
+		body       string
+		wantStatus int
+	}{
+		{name: "no origin", body: initialize, wantStatus: http.StatusOK},
+		{name: "allowed origin", origin: "https://app.example.com", body: initialize, wantStatus: http.StatusOK},
+		{name: "allowed origin with other case", origin: "https://APP.example.com/", body: initialize, wantStatus: http.StatusOK},
+		{name: "rebinding origin", origin: "http://attacker.example", body: initialize, wantStatus: http.StatusForbidden},
+		{name: "null origin", origin: "null", body: initialize, wantStatus: http.StatusForbidden},
+		{name: "oversized body", body: `{"jsonrpc":"2.0","id":1,"method":"ping","params":{"pad":"` + strings.Repeat("x", maxMCPBodySize) + `"}}`, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "malformed body", body: `{"jsonrpc":`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("POST /mcp status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"mcp-go-server/models"
 	"mcp-go-server/response"
 	"mcp-go-server/usecase"
@@ -12,6 +13,13 @@ import (
 
 // VectorSearch performs vector search on repository code
 func VectorSearch(c *gin.Context) {
+	userID, exists := c.Get(models.UserIDKey)
+	if !exists {
+		errRes := response.ErrorClientResponse(http.StatusUnauthorized, "User not authenticated", nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
 	var searchReq models.SearchRequest
 
 	if err := c.ShouldBindJSON(&searchReq); err != nil {
@@ -29,14 +37,19 @@ func VectorSearch(c *gin.Context) {
 
 	// Set default values
 	if searchReq.Branch == "" {
-		searchReq.Branch = "main"
+		searchReq.Branch = usecase.DefaultBranch(userID.(string), searchReq.Repository)
 	}
 	if searchReq.Limit <= 0 {
 		searchReq.Limit = 10
 	}
 
 	// Perform search
-	results, err := usecase.PerformVectorSearch(searchReq)
+	results, err := usecase.PerformVectorSearch(userID.(string), searchReq)
+	if errors.Is(err, models.ErrRepositoryNotFound) {
+		errRes := response.ErrorClientResponse(http.StatusNotFound, "Repository not found", err.Error())
+		c.JSON(http.StatusNotFound, errRes)
+		return
+	}
 	if err != nil {
 		errRes := response.ErrorClientResponse(http.StatusInternalServerError, "Search failed", err.Error())
 		c.JSON(http.StatusInternalServerError, errRes)
@@ -49,6 +62,13 @@ func VectorSearch(c *gin.Context) {
 
 // VectorSearchWithSummary performs vector search and generates AI summary
 func VectorSearchWithSummary(c *gin.Context) {
+	userID, exists := c.Get(models.UserIDKey)
+	if !exists {
+		errRes := response.ErrorClientResponse(http.StatusUnauthorized, "User not authenticated", nil)
+		c.JSON(http.StatusUnauthorized, errRes)
+		return
+	}
+
 	var searchReq models.SearchRequest
 
 	if err := c.ShouldBindJSON(&searchReq); err != nil {
@@ -66,14 +86,24 @@ func VectorSearchWithSummary(c *gin.Context) {
 
 	// Set default values
 	if searchReq.Branch == "" {
-		searchReq.Branch = "main"
+		searchReq.Branch = usecase.DefaultBranch(userID.(string), searchReq.Repository)
 	}
 	if searchReq.Limit <= 0 {
 		searchReq.Limit = 5
 	}
 
 	// Perform search with summary
-	summary, err := usecase.PerformSearchWithSummary(searchReq)
+	summary, err := usecase.PerformSearchWithSummary(userID.(string), searchReq)
+	if errors.Is(err, models.ErrRepositoryNotFound) {
+		errRes := response.ErrorClientResponse(http.StatusNotFound, "Repository not found", err.Error())
+		c.JSON(http.StatusNotFound, errRes)
+		return
+	}
+	if errors.Is(err, models.ErrSummaryModeInvalid) {
+		errRes := response.ErrorClientResponse(http.StatusBadRequest, "Client summaries are only available over MCP", err.Error())
+		c.JSON(http.StatusBadRequest, errRes)
+		return
+	}
 	if err != nil {
 		errRes := response.ErrorClientResponse(http.StatusInternalServerError, "Search with summary failed", err.Error())
 		c.JSON(http.StatusInternalServerError, errRes)
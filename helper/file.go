@@ -1,6 +1,9 @@
 package helper
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"path/filepath"
 	"strings"
 )
@@ -148,25 +151,70 @@ func ContainsBinaryData(content []byte) bool {
 	return false
 }
 
-// ExtractRepoName extracts repository name from URL
+// ExtractRepoName returns the canonical repository identifier of a remote
+// URL, e.g. "owner/repo" for GitHub or "gitlab.com/group/subgroup/project".
+// Names that are not URLs, such as those of uploaded archives, are returned
+// as they are. URLs that fail to parse fall back to their last two path
+// segments.
 func ExtractRepoName(repoURL string) string {
+	if ref, err := ParseRepoURL(repoURL); err == nil {
+		return ref.ID()
+	}
+	if !strings.Contains(repoURL, "://") && !scpLikeRegex.MatchString(repoURL) {
+		return strings.Trim(repoURL, "/")
+	}
+
 	parts := strings.Split(repoURL, "/")
 	if len(parts) < 2 {
 		return repoURL
 	}
+	return parts[len(parts)-2] + "/" + strings.TrimSuffix(parts[len(parts)-1], ".git")
+}
 
-	repoName := parts[len(parts)-1]
-	if strings.HasSuffix(repoName, ".git") {
-		repoName = repoName[:len(repoName)-4]
+// RepoPermalink builds a link to the lines of a file at a commit of the
+// repository with the given canonical identifier. It is empty for chunks
+// without a commit, local directories and generic git remotes.
+func RepoPermalink(repository, commitSHA, filePath string, startLine, endLine int) string {
+	ref, ok := ParseRepoID(repository)
+	if !ok {
+		return ""
 	}
+	return ref.Permalink(commitSHA, filePath, startLine, endLine)
+}
 
-	// Return owner/repo format
-	if len(parts) >= 2 {
-		owner := parts[len(parts)-2]
-		return owner + "/" + repoName
+// LocalRepoName builds the synthetic owner/repo name for a local directory.
+// A short hash of the absolute path keeps directories with the same base
+// name apart.
+func LocalRepoName(dirPath string) string {
+	absPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		absPath = dirPath
 	}
+	sum := sha256.Sum256([]byte(absPath))
+	return "local/" + filepath.Base(absPath) + "-" + hex.EncodeToString(sum[:])[:8]
+}
 
-	return repoName
+// UploadRepoName builds the repository identifier of an archive uploaded by
+// a user under the given name. Uploads live below upload/<user>/, so users
+// can only ever replace their own uploads.
+func UploadRepoName(userID, name string) (string, error) {
+	if !pathSegmentRegex.MatchString(userID) || userID == "." || userID == ".." {
+		return "", fmt.Errorf("user ID %q cannot name an upload", userID)
+	}
+	if err := ValidateRepoName(name); err != nil {
+		return "", err
+	}
+	return "upload/" + userID + "/" + name, nil
+}
+
+// UploadOwner returns the ID of the user who uploaded a repository, if the
+// identifier names an upload
+func UploadOwner(repository string) (string, bool) {
+	segments := strings.SplitN(repository, "/", 3)
+	if len(segments) < 3 || segments[0] != "upload" {
+		return "", false
+	}
+	return segments[1], true
 }
 
 // min returns the minimum of two integers
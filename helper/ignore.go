@@ -0,0 +1,145 @@
+package helper
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// IgnoreFileNames are the ignore files honoured while indexing, in the order
+// their rules are applied. .mcpignore excludes files from indexing only.
+var IgnoreFileNames = []string{".gitignore", ".mcpignore"}
+
+// ignoreRule is a single pattern of an ignore file
+type ignoreRule struct {
+	base    string // Slash-separated directory of the ignore file, "" for the root
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// IgnoreMatcher matches slash-separated relative paths against the rules of
+// .gitignore style files. Later rules take precedence, so files deeper in
+// the tree override their parents.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+// AddRules parses the content of an ignore file located in directory base
+func (m *IgnoreMatcher) AddRules(base, content string) {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimRight(line, " ")
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:] // Escaped leading "!" or "#"
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// Patterns without an inner slash match at any depth
+		if !strings.Contains(line, "/") {
+			line = "**/" + line
+		}
+		rule.pattern = strings.TrimPrefix(line, "/")
+
+		m.rules = append(m.rules, rule)
+	}
+}
+
+// Ignored reports whether relPath is ignored. Callers skip the contents of
+// ignored directories, so a file in an ignored directory is never re-included.
+func (m *IgnoreMatcher) Ignored(relPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		target := relPath
+		if rule.base != "" {
+			if !strings.HasPrefix(relPath, rule.base+"/") {
+				continue
+			}
+			target = strings.TrimPrefix(relPath, rule.base+"/")
+		}
+
+		if MatchGlob(rule.pattern, target) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// MatchGlob matches a slash-separated path against a glob pattern. Besides
+// the path.Match syntax, a "**" segment matches any number of directories.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated "**" and try every possible split
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// MatchPathGlob matches a file path against an include or exclude pattern.
+// Patterns without a slash match the file name at any depth, like "*.go";
+// others match the whole path, like "cmd/**/*.go".
+func MatchPathGlob(pattern, relPath string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		return MatchGlob(pattern, path.Base(relPath))
+	}
+	return MatchGlob(pattern, relPath)
+}
+
+// ValidateGlobs checks the syntax of include or exclude patterns
+func ValidateGlobs(patterns []string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("empty glob pattern")
+		}
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package helper
+
+import "testing"
+
+func TestIgnoreMatcher(t *testing.T) {
+	var m IgnoreMatcher
+	m.AddRules("", "# comment\n\n*.log\n!keep.log\nbuild/\n/root-only.txt\ndocs/*.md\n\\#hash.txt\n")
+	m.AddRules("sub", "local.txt\n!*.log\n")
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{path: "app.log", want: true},
+		{path: "deep/dir/app.log", want: true},
+		{path: "keep.log", want: false},
+		{path: "build", isDir: true, want: true},
+		{path: "build", isDir: false, want: false},
+		{path: "src/build", isDir: true, want: true},
+		{path: "root-only.txt", want: true},
+		{path: "src/root-only.txt", want: false},
+		{path: "docs/readme.md", want: true},
+		{path: "docs/api/readme.md", want: false},
+		{path: "#hash.txt", want: true},
+		{path: "sub/local.txt", want: true},
+		{path: "local.txt", want: false},
+		{path: "sub/app.log", want: false},
+		{path: "subdir/local.txt", want: false},
+		{path: "main.go", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := m.Ignored(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPathGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "*.go", path: "main.go", want: true},
+		{pattern: "*.go", path: "cmd/server/main.go", want: true},
+		{pattern: "*.go", path: "main.go.txt", want: false},
+		{pattern: "cmd/**/*.go", path: "cmd/main.go", want: true},
+		{pattern: "cmd/**/*.go", path: "cmd/server/http/main.go", want: true},
+		{pattern: "cmd/**/*.go", path: "internal/cmd/main.go", want: false},
+		{pattern: "/vendor/**", path: "vendor/a/b.go", want: true},
+		{pattern: "vendor/**", path: "src/vendor/b.go", want: false},
+		{pattern: "**/testdata/**", path: "a/testdata/x.json", want: true},
+		{pattern: "docs/*.md", path: "docs/a/b.md", want: false},
+		{pattern: "[ab].go", path: "b.go", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			if got := MatchPathGlob(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("MatchPathGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateGlobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  bool
+	}{
+		{name: "valid", patterns: []string{"*.go", "cmd/**/*.go", "[a-z]*.md"}},
+		{name: "none"},
+		{name: "empty", patterns: []string{" "}, wantErr: true},
+		{name: "unclosed class", patterns: []string{"*.go", "src/[a-z.go"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateGlobs(tt.patterns); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateGlobs(%q) error = %v, wantErr %v", tt.patterns, err, tt.wantErr)
+			}
+		})
+	}
+}
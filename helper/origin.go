@@ -0,0 +1,27 @@
+package helper
+
+import (
+	"mcp-go-server/database"
+	"strings"
+)
+
+// IsAllowedOrigin reports whether a browser page from origin may talk to
+// the MCP endpoint. Requests without an Origin header come from non-browser
+// clients and are allowed; browser origins must be listed in
+// MCP_ALLOWED_ORIGINS, which keeps pages reached through DNS rebinding out.
+func IsAllowedOrigin(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	if database.DB == nil || database.DB.Config == nil {
+		return false
+	}
+
+	origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+	for _, allowed := range database.DB.Config.MCPAllowedOrigins {
+		if origin == strings.TrimSuffix(allowed, "/") {
+			return true
+		}
+	}
+	return false
+}
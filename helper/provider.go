@@ -0,0 +1,296 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Git hosting providers
+const (
+	ProviderGitHub    = "github"
+	ProviderGitLab    = "gitlab"
+	ProviderBitbucket = "bitbucket"
+	ProviderGitea     = "gitea"
+	ProviderGeneric   = "git" // Any other https or ssh remote
+)
+
+// Provider parses the repository path of a hosting service and links to files
+type Provider interface {
+	// Name returns the provider identifier, e.g. "gitlab"
+	Name() string
+	// ValidatePath checks the slash-separated repository path of a remote URL
+	ValidatePath(segments []string) error
+	// FileURL links to lines of a file at a commit of the repository at
+	// webBase, or returns "" when the provider has no file view
+	FileURL(webBase, commitSHA, filePath string, startLine, endLine int) string
+	// CaseInsensitive reports whether the host ignores the case of
+	// repository paths, so their identifiers are lowercased
+	CaseInsensitive() bool
+}
+
+// RepoRef identifies a repository parsed from a remote URL
+type RepoRef struct {
+	Provider Provider
+	Host     string // Lowercased host without port
+	Path     string // Repository path without .git, lowercased for case-insensitive providers
+}
+
+// ID returns the canonical repository identifier stored in vector metadata.
+// Repositories on github.com keep the short "owner/repo" form; all others are
+// prefixed with their host, e.g. "gitlab.com/group/subgroup/project". GitHub
+// owners never contain dots, so hosts without one, such as "gitserver", get
+// the trailing dot of a fully qualified name: "gitserver./tools".
+func (r RepoRef) ID() string {
+	if r.Host == "github.com" {
+		return r.Path
+	}
+	if !strings.Contains(r.Host, ".") {
+		return r.Host + "./" + r.Path
+	}
+	return r.Host + "/" + r.Path
+}
+
+// WebURL returns the https address of the repository
+func (r RepoRef) WebURL() string {
+	return "https://" + r.Host + "/" + r.Path
+}
+
+// Permalink links to lines of a file at a commit, or returns "" when the
+// provider has no file view
+func (r RepoRef) Permalink(commitSHA, filePath string, startLine, endLine int) string {
+	if commitSHA == "" {
+		return ""
+	}
+	return r.Provider.FileURL(r.WebURL(), commitSHA, filePath, startLine, endLine)
+}
+
+var (
+	// wellKnownHosts maps public hosting services to their provider
+	wellKnownHosts = map[string]string{
+		"github.com":    ProviderGitHub,
+		"gitlab.com":    ProviderGitLab,
+		"bitbucket.org": ProviderBitbucket,
+		"codeberg.org":  ProviderGitea,
+		"gitea.com":     ProviderGitea,
+	}
+
+	providers = map[string]Provider{
+		ProviderGitHub:    ownerRepoProvider{name: ProviderGitHub, blobPath: "blob", lineAnchor: githubLineAnchor},
+		ProviderGitLab:    gitlabProvider{},
+		ProviderBitbucket: ownerRepoProvider{name: ProviderBitbucket, blobPath: "src", lineAnchor: bitbucketLineAnchor},
+		ProviderGitea:     ownerRepoProvider{name: ProviderGitea, blobPath: "src/commit", lineAnchor: githubLineAnchor},
+		ProviderGeneric:   genericProvider{},
+	}
+
+	providerHostsMu sync.RWMutex
+	providerHosts   = map[string]string{}
+
+	pathSegmentRegex = regexp.MustCompile(`^[\w\-.]+$`)
+	scpLikeRegex     = regexp.MustCompile(`^[\w\-.]+@([\w\-.]+):(.+)$`)
+)
+
+// RegisterProviderHosts assigns providers to self-hosted instances, e.g.
+// "git.example.com" to "gitlab". Unregistered hosts other than the well
+// known services are treated as generic git remotes.
+func RegisterProviderHosts(hosts map[string]string) error {
+	providerHostsMu.Lock()
+	defer providerHostsMu.Unlock()
+
+	for host, name := range hosts {
+		if _, ok := providers[name]; !ok {
+			return fmt.Errorf("unknown git provider %q for host %s", name, host)
+		}
+		providerHosts[strings.ToLower(host)] = name
+	}
+	return nil
+}
+
+// providerForHost returns the provider serving host
+func providerForHost(host string) Provider {
+	providerHostsMu.RLock()
+	defer providerHostsMu.RUnlock()
+
+	if name, ok := providerHosts[host]; ok {
+		return providers[name]
+	}
+	if name, ok := wellKnownHosts[host]; ok {
+		return providers[name]
+	}
+	return providers[ProviderGeneric]
+}
+
+// ParseRepoURL parses an https://, ssh:// or scp-like (git@host:path) git
+// remote URL and validates its repository path for the host's provider
+func ParseRepoURL(repoURL string) (RepoRef, error) {
+	var host, repoPath string
+	if match := scpLikeRegex.FindStringSubmatch(repoURL); match != nil && !strings.Contains(repoURL, "://") {
+		host, repoPath = match[1], match[2]
+	} else {
+		parsed, err := url.Parse(repoURL)
+		if err != nil {
+			return RepoRef{}, errors.New("invalid URL format")
+		}
+		if parsed.Scheme != "https" && parsed.Scheme != "ssh" {
+			return RepoRef{}, errors.New("only https:// and ssh:// repository URLs are supported")
+		}
+		if parsed.Scheme == "https" && parsed.User != nil {
+			return RepoRef{}, errors.New("repository URL must not contain credentials")
+		}
+		if parsed.RawQuery != "" || parsed.Fragment != "" {
+			return RepoRef{}, errors.New("repository URL must not contain a query or fragment")
+		}
+		host, repoPath = parsed.Hostname(), parsed.Path
+	}
+	if host == "" {
+		return RepoRef{}, errors.New("repository URL has no host")
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if repoPath == "" {
+		return RepoRef{}, errors.New("repository URL has no repository path")
+	}
+	segments := strings.Split(repoPath, "/")
+	for _, segment := range segments {
+		if !pathSegmentRegex.MatchString(segment) || segment == "." || segment == ".." {
+			return RepoRef{}, fmt.Errorf("invalid repository path segment %q", segment)
+		}
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	provider := providerForHost(host)
+	if err := provider.ValidatePath(segments); err != nil {
+		return RepoRef{}, err
+	}
+	if provider.CaseInsensitive() {
+		repoPath = strings.ToLower(repoPath)
+	}
+	return RepoRef{Provider: provider, Host: host, Path: repoPath}, nil
+}
+
+// ParseRepoID resolves a canonical repository identifier back to its
+// repository, lowercasing the path for case-insensitive providers. It
+// reports false for identifiers without a known host, such as the synthetic
+// local/ names of indexed directories and upload/ names of uploaded
+// archives.
+func ParseRepoID(id string) (RepoRef, bool) {
+	// GitHub owners cannot contain dots, so a dotted first segment is a host
+	segments := strings.Split(id, "/")
+	if !strings.Contains(segments[0], ".") {
+		if len(segments) != 2 || segments[0] == "local" || segments[0] == "upload" {
+			return RepoRef{}, false
+		}
+		return RepoRef{Provider: providers[ProviderGitHub], Host: "github.com", Path: strings.ToLower(id)}, true
+	}
+	host := strings.TrimSuffix(strings.ToLower(segments[0]), ".")
+	if len(segments) < 2 || host == "" {
+		return RepoRef{}, false
+	}
+
+	provider := providerForHost(host)
+	repoPath := strings.Join(segments[1:], "/")
+	if provider.CaseInsensitive() {
+		repoPath = strings.ToLower(repoPath)
+	}
+	return RepoRef{Provider: provider, Host: host, Path: repoPath}, true
+}
+
+// NormalizeRepoID returns the canonical form of a repository identifier
+// given by a user, e.g. "owner/repo" for "Owner/Repo". Identifiers that do
+// not name a remote repository are returned as they are.
+func NormalizeRepoID(id string) string {
+	if ref, ok := ParseRepoID(id); ok {
+		return ref.ID()
+	}
+	return id
+}
+
+// ownerRepoProvider serves hosts with owner/repo paths: GitHub, Bitbucket
+// and Gitea
+type ownerRepoProvider struct {
+	name       string
+	blobPath   string
+	lineAnchor func(startLine, endLine int) string
+}
+
+func (p ownerRepoProvider) Name() string { return p.name }
+
+func (p ownerRepoProvider) ValidatePath(segments []string) error {
+	if len(segments) != 2 {
+		return fmt.Errorf("%s repository URLs must have the form owner/repo", p.name)
+	}
+	return nil
+}
+
+func (p ownerRepoProvider) CaseInsensitive() bool { return true }
+
+func (p ownerRepoProvider) FileURL(webBase, commitSHA, filePath string, startLine, endLine int) string {
+	return fmt.Sprintf("%s/%s/%s/%s", webBase, p.blobPath, commitSHA, escapeFilePath(filePath)) + p.lineAnchor(startLine, endLine)
+}
+
+// gitlabProvider serves GitLab, whose projects may be nested in subgroups
+type gitlabProvider struct{}
+
+func (gitlabProvider) Name() string { return ProviderGitLab }
+
+func (gitlabProvider) ValidatePath(segments []string) error {
+	if len(segments) < 2 {
+		return errors.New("gitlab repository URLs must have the form group/[subgroup/...]project")
+	}
+	for _, segment := range segments {
+		if segment == "-" {
+			return errors.New("gitlab repository URL must point to the project, not a page inside it")
+		}
+	}
+	return nil
+}
+
+func (gitlabProvider) CaseInsensitive() bool { return true }
+
+func (gitlabProvider) FileURL(webBase, commitSHA, filePath string, startLine, endLine int) string {
+	permalink := fmt.Sprintf("%s/-/blob/%s/%s", webBase, commitSHA, escapeFilePath(filePath))
+	if startLine > 0 {
+		permalink += fmt.Sprintf("#L%d-%d", startLine, endLine)
+	}
+	return permalink
+}
+
+// genericProvider serves plain git remotes without a known web interface
+type genericProvider struct{}
+
+func (genericProvider) Name() string { return ProviderGeneric }
+
+func (genericProvider) ValidatePath(segments []string) error { return nil }
+
+func (genericProvider) CaseInsensitive() bool { return false }
+
+func (genericProvider) FileURL(webBase, commitSHA, filePath string, startLine, endLine int) string {
+	return ""
+}
+
+func githubLineAnchor(startLine, endLine int) string {
+	if startLine <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("#L%d-L%d", startLine, endLine)
+}
+
+func bitbucketLineAnchor(startLine, endLine int) string {
+	if startLine <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("#lines-%d:%d", startLine, endLine)
+}
+
+// escapeFilePath escapes each segment of a file path for use in a URL
+func escapeFilePath(filePath string) string {
+	segments := strings.Split(filepath.ToSlash(filePath), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
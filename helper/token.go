@@ -0,0 +1,42 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"mcp-go-server/database"
+)
+
+// HashServiceToken returns the hex-encoded SHA-256 digest of a service token,
+// the format expected in MCP_SECRET_TOKEN_HASHES
+func HashServiceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateServiceToken checks a bearer token against the configured static
+// service credentials and returns the service identity on success
+func ValidateServiceToken(token string) (string, bool) {
+	if database.DB == nil || database.DB.Config == nil || token == "" {
+		return "", false
+	}
+	cfg := database.DB.Config
+
+	matched := false
+	if cfg.MCPSecretToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(cfg.MCPSecretToken)) == 1 {
+		matched = true
+	}
+
+	tokenHash := HashServiceToken(token)
+	for _, hash := range cfg.MCPSecretTokenHashes {
+		if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(hash)) == 1 {
+			matched = true
+		}
+	}
+
+	if !matched {
+		return "", false
+	}
+	return cfg.MCPServiceUserID, true
+}
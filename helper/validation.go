@@ -2,6 +2,7 @@ package helper
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
@@ -25,30 +26,37 @@ func ValidateURL(urlStr string) error {
 	return nil
 }
 
-// ValidateGitHubRepoURL validates GitHub repository URL
-func ValidateGitHubRepoURL(repoURL string) error {
-	if err := ValidateURL(repoURL); err != nil {
-		return err
-	}
+// ValidateRepoURL validates a repository URL of any supported git provider
+func ValidateRepoURL(repoURL string) error {
+	_, err := ParseRepoURL(repoURL)
+	return err
+}
 
-	// Check if it's a GitHub URL
-	if !strings.Contains(repoURL, "github.com") {
-		return errors.New("only GitHub repositories are supported")
+// ValidateRepoName validates the name of an uploaded archive, e.g.
+// "firmware" or "acme/firmware". Uploads are stored below upload/<user>/,
+// so even names that look like remote repositories cannot replace the index
+// of a cloned one. Names under the reserved local/ and upload/ prefixes are
+// rejected.
+func ValidateRepoName(name string) error {
+	if name == "" {
+		return errors.New("repository name is required")
 	}
-
-	// Check URL format
-	githubRegex := regexp.MustCompile(`^https://github\.com/[\w\-\.]+/[\w\-\.]+(?:\.git)?/?$`)
-	if !githubRegex.MatchString(repoURL) {
-		return errors.New("invalid GitHub repository URL format")
+	segments := strings.Split(name, "/")
+	if segments[0] == "local" || segments[0] == "upload" {
+		return fmt.Errorf("repository names under %s/ are reserved", segments[0])
+	}
+	for _, segment := range segments {
+		if !pathSegmentRegex.MatchString(segment) || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid repository name segment %q", segment)
+		}
 	}
-
 	return nil
 }
 
 // ValidateBranch validates branch name
 func ValidateBranch(branch string) error {
 	if branch == "" {
-		return nil // Empty branch is valid (defaults to main)
+		return nil // Empty branch is valid (defaults to the remote's default branch)
 	}
 
 	// Git branch name validation rules
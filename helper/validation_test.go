@@ -0,0 +1,38 @@
+package helper
+
+import "testing"
+
+func TestUploadRepoName(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		upload  string
+		want    string
+		wantErr bool
+	}{
+		{name: "single segment", userID: "alice", upload: "firmware", want: "upload/alice/firmware"},
+		{name: "owner and name", userID: "alice", upload: "acme/firmware", want: "upload/alice/acme/firmware"},
+		{name: "looks like a remote", userID: "alice", upload: "gitlab.com/group/project", want: "upload/alice/gitlab.com/group/project"},
+		{name: "empty", userID: "alice", upload: "", wantErr: true},
+		{name: "reserved local", userID: "alice", upload: "local/firmware", wantErr: true},
+		{name: "reserved upload", userID: "alice", upload: "upload/bob/firmware", wantErr: true},
+		{name: "traversal", userID: "alice", upload: "acme/../firmware", wantErr: true},
+		{name: "empty segment", userID: "alice", upload: "acme//firmware", wantErr: true},
+		{name: "invalid user", userID: "../bob", upload: "firmware", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UploadRepoName(tt.userID, tt.upload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadRepoName(%q, %q) error = %v, wantErr %v", tt.userID, tt.upload, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("UploadRepoName(%q, %q) = %q, want %q", tt.userID, tt.upload, got, tt.want)
+			}
+			if owner, ok := UploadOwner(got); !tt.wantErr && (!ok || owner != tt.userID) {
+				t.Errorf("UploadOwner(%q) = %q, %v, want %q", got, owner, ok, tt.userID)
+			}
+		})
+	}
+}
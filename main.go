@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"mcp-go-server/config"
 	"mcp-go-server/database"
+	"mcp-go-server/helper"
+	"mcp-go-server/mcp"
+	"mcp-go-server/repository"
 	"mcp-go-server/router"
 	"os"
 
@@ -18,6 +23,10 @@ import (
 // @host localhost:8081
 // @BasePath /
 func main() {
+	stdio := flag.Bool("stdio", false, "serve the Model Context Protocol over stdin/stdout instead of HTTP")
+	migrateVectorIDs := flag.Bool("migrate-vector-ids", false, "rewrite vectors stored under legacy IDs to hashed IDs and exit")
+	flag.Parse()
+
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: Error loading .env file: %v", err)
@@ -29,12 +38,40 @@ func main() {
 		log.Fatalf("Error loading config: %v", err)
 	}
 
+	// Recognise self-hosted GitLab, Gitea and GitHub Enterprise servers
+	if err := helper.RegisterProviderHosts(cfg.GitProviderHosts); err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	// Restore the catalog of indexed repositories
+	if err := repository.LoadCatalog(cfg.CatalogDir); err != nil {
+		log.Fatalf("Error loading catalog: %v", err)
+	}
+
 	// Initialize database connections
 	db, err := database.ConnectDatabase(cfg)
 	if err != nil {
 		log.Fatalf("Error connecting to database: %v", err)
 	}
 
+	// One-off migration of vectors indexed before IDs were hashed
+	if *migrateVectorIDs {
+		migrated, err := repository.MigrateVectorIDs(context.Background())
+		if err != nil {
+			log.Fatalf("Vector ID migration failed after %d vectors: %v", migrated, err)
+		}
+		log.Printf("Vector ID migration completed: %d vectors rewritten", migrated)
+		return
+	}
+
+	// Serve MCP over stdio; logs go to stderr so stdout stays protocol-only
+	if *stdio {
+		if err := mcp.NewServer().ServeStdio(context.Background(), os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("MCP stdio server failed: %v", err)
+		}
+		return
+	}
+
 	// Initialize Gin router
 	r := gin.Default()
 
@@ -42,9 +79,9 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"https://mcp-node-server-ui.replit.app", "http://localhost:3000,https://mcp-go-server.replit.app"},
 		AllowMethods:     []string{"GET", "POST", "HEAD", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "Mcp-Session-Id"},
 		AllowCredentials: true,
-		ExposeHeaders:    []string{"Content-Length", "Authorization"},
+		ExposeHeaders:    []string{"Content-Length", "Authorization", "Mcp-Session-Id"},
 		MaxAge:           300,
 	}))
 
@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"mcp-go-server/usecase"
+)
+
+// maxCompletionValues is the most values a completion result may carry
+const maxCompletionValues = 100
+
+func (s *Server) handleComplete(session *Session, params json.RawMessage) (interface{}, *Error) {
+	var completeParams CompleteParams
+	if err := json.Unmarshal(params, &completeParams); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid completion/complete params", Data: err.Error()}
+	}
+
+	args := map[string]string{}
+	if completeParams.Context != nil {
+		for name, value := range completeParams.Context.Arguments {
+			args[name] = value
+		}
+	}
+
+	ref := completeParams.Ref
+	arg := completeParams.Argument
+
+	var values []string
+	switch ref.Type {
+	case "ref/prompt":
+		if _, exists := findPromptTemplate(ref.Name); !exists {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown prompt: %s", ref.Name)}
+		}
+		values = completeRepositoryArgument(session.UserID, arg, args)
+	case "ref/tool":
+		s.mu.RLock()
+		_, exists := s.tools[ref.Name]
+		s.mu.RUnlock()
+		if !exists {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", ref.Name)}
+		}
+		values = completeRepositoryArgument(session.UserID, arg, args)
+	case "ref/resource":
+		if ref.URI != FileResourceTemplate {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown resource template: %s", ref.URI)}
+		}
+		values = completeFileTemplateArgument(session.UserID, arg, args)
+	default:
+		return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unsupported completion reference: %s", ref.Type)}
+	}
+
+	completion := Completion{Values: values, Total: len(values)}
+	if completion.Values == nil {
+		completion.Values = []string{}
+	}
+	if len(completion.Values) > maxCompletionValues {
+		completion.Values = completion.Values[:maxCompletionValues]
+		completion.HasMore = true
+	}
+
+	return CompleteResult{Completion: completion}, nil
+}
+
+// completeRepositoryArgument completes the repository and branch arguments
+// shared by the search tools and prompts
+func completeRepositoryArgument(userID string, arg CompletionArgument, args map[string]string) []string {
+	switch arg.Name {
+	case "repository":
+		return usecase.SuggestRepositories(userID, arg.Value)
+	case "branch":
+		return usecase.SuggestBranches(userID, args["repository"], arg.Value)
+	default:
+		return nil
+	}
+}
+
+// completeFileTemplateArgument completes the variables of FileResourceTemplate
+func completeFileTemplateArgument(userID string, arg CompletionArgument, args map[string]string) []string {
+	repositoryName := ""
+	if args["owner"] != "" && args["name"] != "" {
+		repositoryName = args["owner"] + "/" + args["name"]
+	}
+
+	switch arg.Name {
+	case "owner":
+		return usecase.SuggestOwners(userID, arg.Value)
+	case "name":
+		return usecase.SuggestRepositoryNames(userID, args["owner"], arg.Value)
+	case "branch":
+		return usecase.SuggestBranches(userID, repositoryName, arg.Value)
+	case "path":
+		if repositoryName == "" {
+			return nil
+		}
+		return usecase.SuggestFilePaths(userID, repositoryName, args["branch"], arg.Value)
+	default:
+		return nil
+	}
+}
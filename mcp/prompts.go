@@ -0,0 +1,167 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mcp-go-server/models"
+	"mcp-go-server/usecase"
+	"strings"
+)
+
+// promptContextLimit bounds the retrieved code included in a prompt. It is
+// larger than the server-side summary limit since the client picks the model.
+const promptContextLimit = 12000
+
+// promptSearchLimit is the number of chunks retrieved for a prompt
+const promptSearchLimit = 8
+
+// promptTemplate is a named prompt that retrieves code chunks for a query
+// derived from its arguments and wraps them in task instructions
+type promptTemplate struct {
+	prompt       Prompt
+	query        func(args map[string]string) string
+	instructions func(args map[string]string) string
+}
+
+// repositoryArguments are accepted by every prompt
+var repositoryArguments = []PromptArgument{
+	{Name: "repository", Description: "Indexed repository in owner/name format", Required: true},
+	{Name: "branch", Description: "Indexed branch (defaults to the most recently indexed branch)"},
+}
+
+var promptTemplates = []promptTemplate{
+	{
+		prompt: Prompt{
+			Name:        "explain-code",
+			Description: "Explain how a feature, function or file works using the most relevant indexed code.",
+			Arguments: append(append([]PromptArgument{}, repositoryArguments...),
+				PromptArgument{Name: "topic", Description: "Function, type, file or behaviour to explain", Required: true}),
+		},
+		query: func(args map[string]string) string {
+			return args["topic"]
+		},
+		instructions: func(args map[string]string) string {
+			return fmt.Sprintf("Explain how %q works in the repository %s. Walk through the relevant code step by step, "+
+				"describe the data flow and call out important types and functions. Cite file paths for every claim.",
+				args["topic"], args["repository"])
+		},
+	},
+	{
+		prompt: Prompt{
+			Name:        "find-bug-candidates",
+			Description: "Review indexed code for likely bugs such as unchecked errors, races and edge cases.",
+			Arguments: append(append([]PromptArgument{}, repositoryArguments...),
+				PromptArgument{Name: "area", Description: "Feature or component to focus the review on"}),
+		},
+		query: func(args map[string]string) string {
+			query := "error handling, concurrency, input validation and edge cases"
+			if area := args["area"]; area != "" {
+				query = area + " " + query
+			}
+			return query
+		},
+		instructions: func(args map[string]string) string {
+			focus := "the code below"
+			if area := args["area"]; area != "" {
+				focus = fmt.Sprintf("the %s code below", area)
+			}
+			return fmt.Sprintf("Review %s from the repository %s and list bug candidates. For each candidate give the "+
+				"file path, the problematic code, why it is likely wrong and a suggested fix. Order candidates by severity "+
+				"and say so explicitly if you find nothing suspicious.", focus, args["repository"])
+		},
+	},
+	{
+		prompt: Prompt{
+			Name:        "onboarding-tour",
+			Description: "Give a new contributor a guided tour of an indexed repository.",
+			Arguments: append(append([]PromptArgument{}, repositoryArguments...),
+				PromptArgument{Name: "focus", Description: "Area of the codebase the newcomer will work on"}),
+		},
+		query: func(args map[string]string) string {
+			query := "project overview, entry point, main, configuration, routing and architecture"
+			if focus := args["focus"]; focus != "" {
+				query = focus + " " + query
+			}
+			return query
+		},
+		instructions: func(args map[string]string) string {
+			tour := "Give a new contributor an onboarding tour of the repository %s."
+			if focus := args["focus"]; focus != "" {
+				tour += fmt.Sprintf(" They will mostly work on %s.", focus)
+			}
+			return fmt.Sprintf(tour+" Describe the overall architecture, the entry points, how the main packages fit "+
+				"together and which files to read first, citing file paths from the code below.", args["repository"])
+		},
+	},
+}
+
+func findPromptTemplate(name string) (promptTemplate, bool) {
+	for _, template := range promptTemplates {
+		if template.prompt.Name == name {
+			return template, true
+		}
+	}
+	return promptTemplate{}, false
+}
+
+func (s *Server) handlePromptsList() (interface{}, *Error) {
+	prompts := make([]Prompt, 0, len(promptTemplates))
+	for _, template := range promptTemplates {
+		prompts = append(prompts, template.prompt)
+	}
+	return ListPromptsResult{Prompts: prompts}, nil
+}
+
+func (s *Server) handlePromptsGet(session *Session, params json.RawMessage) (interface{}, *Error) {
+	var getParams GetPromptParams
+	if err := json.Unmarshal(params, &getParams); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid prompts/get params", Data: err.Error()}
+	}
+
+	template, exists := findPromptTemplate(getParams.Name)
+	if !exists {
+		return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown prompt: %s", getParams.Name)}
+	}
+
+	args := getParams.Arguments
+	if args == nil {
+		args = map[string]string{}
+	}
+	for _, arg := range template.prompt.Arguments {
+		if arg.Required && strings.TrimSpace(args[arg.Name]) == "" {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("missing required argument: %s", arg.Name)}
+		}
+	}
+
+	branch := args["branch"]
+	if branch == "" {
+		branch = usecase.DefaultBranch(session.UserID, args["repository"])
+	}
+
+	searchContext, _, err := usecase.RetrieveSearchContext(session.UserID, models.SearchRequest{
+		Query:      template.query(args),
+		Repository: args["repository"],
+		Branch:     branch,
+		Limit:      promptSearchLimit,
+	}, promptContextLimit)
+	if err != nil {
+		if errors.Is(err, models.ErrRepositoryNotFound) {
+			return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("repository %s@%s is not indexed", args["repository"], branch)}
+		}
+		return nil, &Error{Code: CodeInternalError, Message: "failed to retrieve code context", Data: err.Error()}
+	}
+
+	return GetPromptResult{
+		Description: template.prompt.Description,
+		Messages: []PromptMessage{
+			{
+				Role: "user",
+				Content: Content{
+					Type: "text",
+					Text: template.instructions(args) + "\n\n" + searchContext,
+				},
+			},
+		},
+	}, nil
+}
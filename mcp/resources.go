@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mcp-go-server/domain"
+	"mcp-go-server/models"
+	"mcp-go-server/usecase"
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ResourceScheme is the URI scheme for indexed files
+const ResourceScheme = "repo://"
+
+// FileResourceTemplate is the URI template for indexed files
+const FileResourceTemplate = "repo://{owner}/{name}@{branch}/{+path}"
+
+// resourcePageSize is the number of resources returned per resources/list page
+const resourcePageSize = 100
+
+// FileURI builds the resource URI of an indexed file. The branch is
+// path-escaped so branches containing slashes stay unambiguous.
+func FileURI(repository, branch, filePath string) string {
+	return ResourceScheme + repository + "@" + url.PathEscape(branch) + "/" + filePath
+}
+
+// ParseFileURI splits a resource URI into repository, branch and path
+func ParseFileURI(uri string) (repository, branch, filePath string, err error) {
+	repository, branch, filePath, err = parseResourceURI(uri)
+	if err != nil {
+		return "", "", "", err
+	}
+	if filePath == "" {
+		return "", "", "", errors.New("resource URI is missing a file path")
+	}
+	return repository, branch, filePath, nil
+}
+
+// parseResourceURI splits a resource URI that names either a file or a
+// whole branch (repo://owner/name@branch) into its parts
+func parseResourceURI(uri string) (repository, branch, filePath string, err error) {
+	if !strings.HasPrefix(uri, ResourceScheme) {
+		return "", "", "", fmt.Errorf("unsupported resource URI scheme: %s", uri)
+	}
+	rest := strings.TrimPrefix(uri, ResourceScheme)
+
+	at := strings.Index(rest, "@")
+	if at <= 0 || at == len(rest)-1 {
+		return "", "", "", errors.New("resource URI must have the form repo://owner/name@branch/path")
+	}
+	repository = rest[:at]
+
+	escapedBranch := rest[at+1:]
+	if slash := strings.Index(escapedBranch, "/"); slash >= 0 {
+		escapedBranch, filePath = escapedBranch[:slash], escapedBranch[slash+1:]
+	}
+	if escapedBranch == "" {
+		return "", "", "", errors.New("resource URI must have the form repo://owner/name@branch/path")
+	}
+
+	branch, err = url.PathUnescape(escapedBranch)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid branch in resource URI: %w", err)
+	}
+
+	return repository, branch, filePath, nil
+}
+
+// mimeTypeForPath guesses a MIME type from the file extension
+func mimeTypeForPath(filePath string) string {
+	if mimeType := mime.TypeByExtension(path.Ext(filePath)); strings.HasPrefix(mimeType, "text/") ||
+		strings.HasPrefix(mimeType, "application/json") {
+		return mimeType
+	}
+	return "text/plain"
+}
+
+func fileResource(file domain.IndexedFile) Resource {
+	return Resource{
+		URI:         FileURI(file.Repository, file.Branch, file.Path),
+		Name:        file.Path,
+		Description: fmt.Sprintf("%s file from %s@%s", file.Language, file.Repository, file.Branch),
+		MimeType:    mimeTypeForPath(file.Path),
+		Size:        file.Size,
+	}
+}
+
+func (s *Server) handleResourcesList(session *Session, params json.RawMessage) (interface{}, *Error) {
+	var listParams PaginatedParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &listParams); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: "invalid resources/list params", Data: err.Error()}
+		}
+	}
+
+	offset := 0
+	if listParams.Cursor != "" {
+		var err error
+		if offset, err = strconv.Atoi(listParams.Cursor); err != nil || offset < 0 {
+			return nil, &Error{Code: CodeInvalidParams, Message: "invalid cursor"}
+		}
+	}
+
+	files := usecase.ListIndexedFiles(session.UserID)
+	result := ListResourcesResult{Resources: []Resource{}}
+	for i := offset; i < len(files) && i < offset+resourcePageSize; i++ {
+		result.Resources = append(result.Resources, fileResource(files[i]))
+	}
+	if offset+resourcePageSize < len(files) {
+		result.NextCursor = strconv.Itoa(offset + resourcePageSize)
+	}
+
+	return result, nil
+}
+
+func (s *Server) handleResourceTemplatesList() (interface{}, *Error) {
+	return ListResourceTemplatesResult{
+		ResourceTemplates: []ResourceTemplate{
+			{
+				URITemplate: FileResourceTemplate,
+				Name:        "Indexed file",
+				Description: "Full content of a file from an indexed repository branch. Branch names containing '/' must be percent-encoded.",
+			},
+		},
+	}, nil
+}
+
+func (s *Server) handleResourcesRead(session *Session, params json.RawMessage) (interface{}, *Error) {
+	var readParams ReadResourceParams
+	if err := json.Unmarshal(params, &readParams); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid resources/read params", Data: err.Error()}
+	}
+
+	repository, branch, filePath, err := ParseFileURI(readParams.URI)
+	if err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	file, err := usecase.GetIndexedFile(session.UserID, repository, branch, filePath)
+	if err != nil {
+		if errors.Is(err, models.ErrFileNotFound) {
+			return nil, &Error{Code: CodeResourceNotFound, Message: "resource not found", Data: map[string]string{"uri": readParams.URI}}
+		}
+		return nil, &Error{Code: CodeInternalError, Message: err.Error()}
+	}
+
+	return ReadResourceResult{
+		Contents: []ResourceContents{
+			{
+				URI:      readParams.URI,
+				MimeType: mimeTypeForPath(file.Path),
+				Text:     file.Content,
+			},
+		},
+	}, nil
+}
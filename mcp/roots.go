@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mcp-go-server/models"
+	"mcp-go-server/usecase"
+	"net/url"
+	"path/filepath"
+)
+
+// ToolIndexWorkspaceRoots indexes the workspace roots announced by the client
+const ToolIndexWorkspaceRoots = "index_workspace_roots"
+
+// rootIndexResult reports the outcome of indexing one workspace root
+type rootIndexResult struct {
+	URI   string                `json:"uri"`
+	Index *models.IndexResponse `json:"index,omitempty"`
+	Error string                `json:"error,omitempty"`
+}
+
+// registerRootsTools registers the workspace roots indexing tool
+func registerRootsTools(s *Server) {
+	s.AddTool(Tool{
+		Name:        ToolIndexWorkspaceRoots,
+		Description: "Index the local workspace directories (MCP roots) announced by the client, including uncommitted changes. They are re-indexed automatically whenever the roots change. Only available over stdio.",
+		InputSchema: SchemaFor(struct{}{}),
+	}, s.indexWorkspaceRootsTool)
+}
+
+func (s *Server) indexWorkspaceRootsTool(ctx context.Context, session *Session, args json.RawMessage) (interface{}, error) {
+	if !session.Local {
+		return nil, errors.New("workspace roots can only be indexed by local (stdio) clients")
+	}
+
+	session.mu.Lock()
+	supportsRoots := session.ClientCapabilities.Roots != nil
+	session.mu.Unlock()
+	if !supportsRoots {
+		return nil, errors.New("client does not advertise the roots capability")
+	}
+
+	results, err := s.indexRoots(ctx, session, newIndexProgressReporter(ctx, session))
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the index in sync with later roots changes
+	session.mu.Lock()
+	session.watchRoots = true
+	session.mu.Unlock()
+
+	return results, nil
+}
+
+// indexRoots asks the client for its roots and indexes every file:// root
+func (s *Server) indexRoots(ctx context.Context, session *Session, onProgress func(models.IndexProgress)) ([]rootIndexResult, error) {
+	raw, err := session.Request(ctx, MethodRootsList, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rootsResult ListRootsResult
+	if err := json.Unmarshal(raw, &rootsResult); err != nil {
+		return nil, fmt.Errorf("invalid roots/list result: %w", err)
+	}
+
+	results := []rootIndexResult{}
+	for _, root := range rootsResult.Roots {
+		result := rootIndexResult{URI: root.URI}
+
+		dirPath, err := rootPath(root.URI)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		indexResp, err := usecase.IndexLocalDirectory(ctx, session.UserID, dirPath, onProgress)
+		if errors.Is(err, models.ErrIndexingCancelled) {
+			return nil, err
+		}
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Index = &indexResp
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// rootPath converts a file:// root URI to a local directory path
+func rootPath(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid root URI: %w", err)
+	}
+	if parsed.Scheme != "file" {
+		return "", fmt.Errorf("unsupported root URI scheme: %s", parsed.Scheme)
+	}
+	if parsed.Host != "" && parsed.Host != "localhost" {
+		return "", fmt.Errorf("root %s is not on this machine", uri)
+	}
+	return filepath.FromSlash(parsed.Path), nil
+}
+
+// handleRootsListChanged re-indexes the workspace roots in the background
+// once the client has opted in through index_workspace_roots
+func (s *Server) handleRootsListChanged(session *Session) (interface{}, *Error) {
+	session.mu.Lock()
+	if !session.watchRoots {
+		session.mu.Unlock()
+		return nil, nil
+	}
+
+	// Supersede any re-index still running for the previous roots
+	if session.rootsCancel != nil {
+		session.rootsCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	session.rootsCancel = cancel
+	session.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		log.Printf("🔄 Workspace roots changed for session %s, re-indexing", session.ID)
+		results, err := s.indexRoots(ctx, session, nil)
+		if err != nil {
+			log.Printf("⚠️  Failed to re-index workspace roots: %v", err)
+			return
+		}
+		log.Printf("✅ Re-indexed %d workspace roots", len(results))
+	}()
+
+	return nil, nil
+}
+
+// stopRootsIndexing cancels a background roots re-index, if any
+func (sess *Session) stopRootsIndexing() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.watchRoots = false
+	if sess.rootsCancel != nil {
+		sess.rootsCancel()
+		sess.rootsCancel = nil
+	}
+}
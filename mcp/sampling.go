@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mcp-go-server/models"
+	"mcp-go-server/usecase"
+)
+
+// samplingSummarizer completes summary prompts with the client's own model
+// through sampling/createMessage. It returns nil if the client does not
+// support sampling.
+func samplingSummarizer(session *Session) usecase.Summarizer {
+	if !session.SupportsSampling() {
+		return nil
+	}
+
+	return func(ctx context.Context, prompt models.SummaryPrompt) (string, error) {
+		raw, err := session.Request(ctx, MethodSamplingCreateMessage, CreateMessageParams{
+			Messages: []SamplingMessage{
+				{Role: "user", Content: Content{Type: "text", Text: prompt.UserPrompt}},
+			},
+			SystemPrompt:     prompt.SystemPrompt,
+			IncludeContext:   "none",
+			Temperature:      prompt.Temperature,
+			MaxTokens:        prompt.MaxTokens,
+			ModelPreferences: &ModelPreferences{SpeedPriority: 0.5, IntelligencePriority: 0.5},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		var result CreateMessageResult
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return "", fmt.Errorf("invalid sampling result: %w", err)
+		}
+		if result.Content.Type != "text" || result.Content.Text == "" {
+			return "", errors.New("client returned no text completion")
+		}
+
+		return result.Content.Text, nil
+	}
+}
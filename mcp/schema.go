@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONSchema is the subset of JSON Schema used for tool input schemas
+type JSONSchema struct {
+	Type                 string                 `json:"type"`
+	Description          string                 `json:"description,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Maximum              *int                   `json:"maximum,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// SchemaFor generates a JSON schema from the json, validate and description
+// struct tags of the given value
+func SchemaFor(v interface{}) *JSONSchema {
+	return schemaForType(reflect.TypeOf(v))
+}
+
+func schemaForType(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return schemaForStruct(t)
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	default:
+		return &JSONSchema{Type: "string"}
+	}
+}
+
+func schemaForStruct(t reflect.Type) *JSONSchema {
+	noExtra := false
+	schema := &JSONSchema{
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{},
+		AdditionalProperties: &noExtra,
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := schemaForType(field.Type)
+		prop.Description = field.Tag.Get("description")
+
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			switch {
+			case rule == "required":
+				schema.Required = append(schema.Required, name)
+			case rule == "url":
+				prop.Format = "uri"
+			case strings.HasPrefix(rule, "min=") && prop.Type == "string":
+				if minLength, err := strconv.Atoi(strings.TrimPrefix(rule, "min=")); err == nil {
+					prop.MinLength = &minLength
+				}
+			case strings.HasPrefix(rule, "min=") && prop.Type == "integer":
+				if minimum, err := strconv.Atoi(strings.TrimPrefix(rule, "min=")); err == nil {
+					prop.Minimum = &minimum
+				}
+			case strings.HasPrefix(rule, "max=") && prop.Type == "integer":
+				if maximum, err := strconv.Atoi(strings.TrimPrefix(rule, "max=")); err == nil {
+					prop.Maximum = &maximum
+				}
+			}
+		}
+
+		schema.Properties[name] = prop
+	}
+
+	return schema
+}
@@ -0,0 +1,689 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mcp-go-server/usecase"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ServerName and ServerVersion identify this server during initialize
+const (
+	ServerName    = "mcp-go-server"
+	ServerVersion = "1.0.0"
+)
+
+// ToolHandler executes a tool call with the raw JSON arguments
+type ToolHandler func(ctx context.Context, session *Session, args json.RawMessage) (interface{}, error)
+
+type registeredTool struct {
+	tool    Tool
+	handler ToolHandler
+}
+
+// Sender delivers a JSON-RPC message to the client
+type Sender func(msg interface{}) error
+
+// ErrSessionNotFound is returned for unknown or terminated sessions
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrNoClientStream is returned when a message cannot be delivered because
+// the client has no open stream
+var ErrNoClientStream = errors.New("no open stream to client")
+
+// Server dispatches MCP JSON-RPC messages to registered handlers
+type Server struct {
+	mu        sync.RWMutex
+	tools     map[string]registeredTool
+	toolOrder []string
+
+	sessionsMu sync.RWMutex
+	sessions   map[string]*Session
+}
+
+// sessionIdleTimeout is how long a session may go unused before it expires.
+// Sessions with an open stream or a request in progress never expire.
+const sessionIdleTimeout = 30 * time.Minute
+
+// maxSessionsPerUser bounds the sessions of a single user; creating one more
+// closes the user's least recently used session
+const maxSessionsPerUser = 16
+
+// clientRequestTimeout bounds how long the server waits for the client to
+// answer a server-initiated request, e.g. while a user approves sampling
+const clientRequestTimeout = 5 * time.Minute
+
+// Session holds the per-connection state of an MCP client
+type Session struct {
+	ID                 string
+	UserID             string
+	ClientInfo         Implementation
+	ClientCapabilities ClientCapabilities
+	// Local is set for sessions on the server's own machine (stdio), the
+	// only ones allowed to index file:// roots
+	Local bool
+
+	mu          sync.Mutex
+	lastUsed    time.Time
+	initialized bool
+	send        Sender
+	inFlight    map[string]*inFlightRequest
+	pending     map[string]chan incomingResponse
+	nextID      int64
+
+	subscriptions map[string]bool
+
+	watchRoots  bool
+	rootsCancel context.CancelFunc
+}
+
+// inFlightRequest tracks a request that the client may cancel
+type inFlightRequest struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+type progressTokenKey struct{}
+
+type senderKey struct{}
+
+// WithSender returns a context whose messages are delivered through the
+// given sender, e.g. the SSE stream answering a particular HTTP request
+func WithSender(ctx context.Context, send Sender) context.Context {
+	return context.WithValue(ctx, senderKey{}, send)
+}
+
+// NewServer creates a server with the code search tools registered
+func NewServer() *Server {
+	s := &Server{
+		tools:    make(map[string]registeredTool),
+		sessions: make(map[string]*Session),
+	}
+	registerSearchTools(s)
+	registerRootsTools(s)
+	usecase.AddIndexListener(s.notifyRepositoryIndexed)
+	return s
+}
+
+// CreateSession registers a new session for the given user. Expired
+// sessions are dropped, and so is the user's least recently used session
+// once they hold maxSessionsPerUser.
+func (s *Server) CreateSession(userID string) *Session {
+	session := &Session{ID: newSessionID(), UserID: userID, lastUsed: time.Now()}
+
+	s.sessionsMu.Lock()
+	removed := s.expireSessions(userID)
+	s.sessions[session.ID] = session
+	s.sessionsMu.Unlock()
+
+	for _, old := range removed {
+		old.stopRootsIndexing()
+		log.Printf("⌛ MCP session expired: %s", old.ID)
+	}
+	return session
+}
+
+// expireSessions removes idle sessions and, when userID is at the session
+// limit, the user's least recently used one. The caller must hold sessionsMu.
+func (s *Server) expireSessions(userID string) []*Session {
+	now := time.Now()
+	var removed []*Session
+	var oldest *Session
+	var oldestUsed time.Time
+	count := 0
+	for id, session := range s.sessions {
+		lastUsed, busy := session.activity()
+		if !busy && now.Sub(lastUsed) > sessionIdleTimeout {
+			delete(s.sessions, id)
+			removed = append(removed, session)
+			continue
+		}
+		if session.UserID == userID {
+			count++
+			if oldest == nil || lastUsed.Before(oldestUsed) {
+				oldest, oldestUsed = session, lastUsed
+			}
+		}
+	}
+
+	if count >= maxSessionsPerUser {
+		delete(s.sessions, oldest.ID)
+		removed = append(removed, oldest)
+	}
+	return removed
+}
+
+// GetSession returns the session with the given ID owned by userID and
+// marks it as used
+func (s *Server) GetSession(id, userID string) (*Session, error) {
+	s.sessionsMu.RLock()
+	session, exists := s.sessions[id]
+	s.sessionsMu.RUnlock()
+
+	// Sessions belonging to other users are reported as missing
+	if !exists || session.UserID != userID {
+		return nil, ErrSessionNotFound
+	}
+
+	if lastUsed, busy := session.activity(); !busy && time.Since(lastUsed) > sessionIdleTimeout {
+		s.CloseSession(id)
+		return nil, ErrSessionNotFound
+	}
+	session.touch()
+	return session, nil
+}
+
+// CloseSession terminates the session with the given ID
+func (s *Server) CloseSession(id string) {
+	s.sessionsMu.Lock()
+	session, exists := s.sessions[id]
+	delete(s.sessions, id)
+	s.sessionsMu.Unlock()
+
+	if exists {
+		session.stopRootsIndexing()
+	}
+}
+
+// touch marks the session as used now
+func (sess *Session) touch() {
+	sess.mu.Lock()
+	sess.lastUsed = time.Now()
+	sess.mu.Unlock()
+}
+
+// activity returns when the session was last used and whether it is busy
+// with an open stream or a request in progress
+func (sess *Session) activity() (time.Time, bool) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.lastUsed, sess.send != nil || len(sess.inFlight) > 0
+}
+
+// SetSender sets the stream used for messages not tied to a request.
+// Passing nil detaches the current stream.
+func (sess *Session) SetSender(send Sender) {
+	sess.mu.Lock()
+	sess.send = send
+	sess.mu.Unlock()
+}
+
+// HasSender reports whether a standalone stream is attached
+func (sess *Session) HasSender() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.send != nil
+}
+
+// Notify sends a notification to the client, preferring the stream of the
+// request carried by ctx and falling back to the standalone stream
+func (sess *Session) Notify(ctx context.Context, method string, params interface{}) error {
+	return sess.deliver(ctx, Notification{JSONRPC: JSONRPCVersion, Method: method, Params: params})
+}
+
+func (sess *Session) deliver(ctx context.Context, msg interface{}) error {
+	if send, ok := ctx.Value(senderKey{}).(Sender); ok && send != nil {
+		if err := send(msg); err == nil {
+			return nil
+		}
+	}
+
+	sess.mu.Lock()
+	send := sess.send
+	sess.mu.Unlock()
+	if send == nil {
+		return ErrNoClientStream
+	}
+	return send(msg)
+}
+
+// NotifyProgress sends notifications/progress for the request carried by
+// ctx. It does nothing if the client did not ask for progress.
+func (sess *Session) NotifyProgress(ctx context.Context, progress, total float64, message string) error {
+	token, ok := ctx.Value(progressTokenKey{}).(json.RawMessage)
+	if !ok || len(token) == 0 {
+		return nil
+	}
+
+	return sess.Notify(ctx, MethodProgress, ProgressParams{
+		ProgressToken: token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+}
+
+// SupportsSampling reports whether the client accepts sampling requests
+func (sess *Session) SupportsSampling() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.ClientCapabilities.Sampling != nil
+}
+
+// Request sends a request to the client and waits for its result. If ctx
+// is cancelled first, the client is told to stop via notifications/cancelled.
+func (sess *Session) Request(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	id := json.RawMessage(fmt.Sprintf(`"srv-%d"`, atomic.AddInt64(&sess.nextID, 1)))
+	key := requestKey(id)
+	responses := make(chan incomingResponse, 1)
+
+	sess.mu.Lock()
+	if sess.pending == nil {
+		sess.pending = make(map[string]chan incomingResponse)
+	}
+	sess.pending[key] = responses
+	sess.mu.Unlock()
+
+	defer func() {
+		sess.mu.Lock()
+		delete(sess.pending, key)
+		sess.mu.Unlock()
+	}()
+
+	request := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  interface{}     `json:"params,omitempty"`
+	}{JSONRPC: JSONRPCVersion, ID: id, Method: method, Params: params}
+	if err := sess.deliver(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	timer := time.NewTimer(clientRequestTimeout)
+	defer timer.Stop()
+
+	select {
+	case resp := <-responses:
+		if resp.Error != nil {
+			return nil, fmt.Errorf("client rejected %s: %s", method, resp.Error.Message)
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		sess.Notify(context.Background(), MethodCancelled, CancelledParams{RequestID: id, Reason: ctx.Err().Error()})
+		return nil, ctx.Err()
+	case <-timer.C:
+		sess.Notify(context.Background(), MethodCancelled, CancelledParams{RequestID: id, Reason: "timed out"})
+		return nil, fmt.Errorf("client did not answer %s within %v", method, clientRequestTimeout)
+	}
+}
+
+// resolveResponse hands a client response to the waiting Request call
+func (sess *Session) resolveResponse(resp incomingResponse) bool {
+	sess.mu.Lock()
+	responses, exists := sess.pending[requestKey(resp.ID)]
+	sess.mu.Unlock()
+	if !exists {
+		return false
+	}
+
+	// Duplicate responses are dropped rather than blocking the reader
+	select {
+	case responses <- resp:
+	default:
+	}
+	return true
+}
+
+// trackRequest registers a cancellable in-flight request
+func (sess *Session) trackRequest(id json.RawMessage, cancel context.CancelFunc) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.inFlight == nil {
+		sess.inFlight = make(map[string]*inFlightRequest)
+	}
+	sess.inFlight[requestKey(id)] = &inFlightRequest{cancel: cancel}
+}
+
+// finishRequest unregisters a request and reports whether it was cancelled
+func (sess *Session) finishRequest(id json.RawMessage) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	key := requestKey(id)
+	request, exists := sess.inFlight[key]
+	if !exists {
+		return false
+	}
+	delete(sess.inFlight, key)
+	request.cancel()
+	return request.cancelled
+}
+
+// cancelRequest cancels an in-flight request if it is still running
+func (sess *Session) cancelRequest(id json.RawMessage) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	request, exists := sess.inFlight[requestKey(id)]
+	if !exists {
+		return false
+	}
+	request.cancelled = true
+	request.cancel()
+	return true
+}
+
+// requestKey normalizes a JSON-RPC ID for use as a map key
+func requestKey(id json.RawMessage) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, id); err != nil {
+		return string(id)
+	}
+	return compact.String()
+}
+
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate session ID: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// AddTool registers a tool with the server
+func (s *Server) AddTool(tool Tool, handler ToolHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tools[tool.Name]; !exists {
+		s.toolOrder = append(s.toolOrder, tool.Name)
+	}
+	s.tools[tool.Name] = registeredTool{tool: tool, handler: handler}
+}
+
+// Handle processes a JSON-RPC payload, which may be a single message or a
+// batch. It returns a *Response, a []*Response for batches, or nil when
+// nothing needs to be sent back.
+func (s *Server) Handle(ctx context.Context, session *Session, data []byte) interface{} {
+	// Long requests count as use until they finish
+	defer session.touch()
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '[' {
+		if resp := s.HandleMessage(ctx, session, data); resp != nil {
+			return resp
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(data, &batch); err != nil {
+		return errorResponse(nil, &Error{Code: CodeParseError, Message: "parse error", Data: err.Error()})
+	}
+	if len(batch) == 0 {
+		return errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "empty batch"})
+	}
+
+	// Tool calls run concurrently; everything else is handled in order so
+	// that e.g. initialize precedes notifications/initialized
+	results := make([]*Response, len(batch))
+	var wg sync.WaitGroup
+	for i, msg := range batch {
+		if !IsToolCall(msg) {
+			results[i] = s.HandleMessage(ctx, session, msg)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, msg json.RawMessage) {
+			defer wg.Done()
+			results[i] = s.HandleMessage(ctx, session, msg)
+		}(i, msg)
+	}
+	wg.Wait()
+
+	var responses []*Response
+	for _, resp := range results {
+		if resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
+
+// PayloadInfo describes the messages contained in a JSON-RPC payload
+type PayloadInfo struct {
+	HasRequests   bool
+	HasInitialize bool
+}
+
+// InspectPayload reports which kinds of messages a payload contains
+func InspectPayload(data []byte) (PayloadInfo, error) {
+	var info PayloadInfo
+	var msgs []Request
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return info, err
+		}
+	} else {
+		var msg Request
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return info, err
+		}
+		msgs = []Request{msg}
+	}
+
+	for _, msg := range msgs {
+		if msg.Method != "" && !msg.IsNotification() {
+			info.HasRequests = true
+		}
+		if msg.Method == MethodInitialize {
+			info.HasInitialize = true
+		}
+	}
+	return info, nil
+}
+
+// IsToolCall reports whether data is a single tools/call request. Tool
+// calls may run for minutes and wait on the client, so transports handle
+// them concurrently while processing every other message in order.
+func IsToolCall(data []byte) bool {
+	var msg Request
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return false
+	}
+	return msg.Method == MethodToolsCall && !msg.IsNotification()
+}
+
+// HandleMessage processes a single JSON-RPC message and returns the
+// response, or nil if the message was a notification
+func (s *Server) HandleMessage(ctx context.Context, session *Session, data []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return errorResponse(nil, &Error{Code: CodeParseError, Message: "parse error", Data: err.Error()})
+	}
+
+	// Responses to server-initiated requests carry an ID but no method
+	if req.JSONRPC == JSONRPCVersion && req.Method == "" && !req.IsNotification() {
+		var resp incomingResponse
+		if err := json.Unmarshal(data, &resp); err == nil && (resp.Result != nil || resp.Error != nil) {
+			if !session.resolveResponse(resp) {
+				log.Printf("⚠️  Ignoring MCP response to unknown request %s", string(resp.ID))
+			}
+			return nil
+		}
+	}
+
+	if req.JSONRPC != JSONRPCVersion || req.Method == "" {
+		if req.IsNotification() {
+			return nil
+		}
+		return errorResponse(req.ID, &Error{Code: CodeInvalidRequest, Message: "invalid request"})
+	}
+
+	if !req.IsNotification() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		session.trackRequest(req.ID, cancel)
+	}
+
+	result, rpcErr := s.dispatch(ctx, session, &req)
+	if !req.IsNotification() && session.finishRequest(req.ID) {
+		// Cancelled requests must not be answered
+		log.Printf("🛑 MCP request %s cancelled by client", string(req.ID))
+		return nil
+	}
+	if req.IsNotification() {
+		if rpcErr != nil {
+			log.Printf("⚠️  MCP notification %s failed: %v", req.Method, rpcErr)
+		}
+		return nil
+	}
+	if rpcErr != nil {
+		return errorResponse(req.ID, rpcErr)
+	}
+
+	return &Response{JSONRPC: JSONRPCVersion, ID: req.ID, Result: result}
+}
+
+func (s *Server) dispatch(ctx context.Context, session *Session, req *Request) (interface{}, *Error) {
+	switch req.Method {
+	case MethodInitialize:
+		return s.handleInitialize(session, req.Params)
+	case MethodInitialized:
+		session.mu.Lock()
+		session.initialized = true
+		session.mu.Unlock()
+		return nil, nil
+	case MethodPing:
+		return struct{}{}, nil
+	case MethodCancelled:
+		return s.handleCancelled(session, req.Params)
+	case MethodRootsListChanged:
+		return s.handleRootsListChanged(session)
+	case MethodToolsList:
+		return s.handleToolsList()
+	case MethodToolsCall:
+		return s.handleToolsCall(ctx, session, req.Params)
+	case MethodResourcesList:
+		return s.handleResourcesList(session, req.Params)
+	case MethodResourcesTemplatesList:
+		return s.handleResourceTemplatesList()
+	case MethodResourcesRead:
+		return s.handleResourcesRead(session, req.Params)
+	case MethodResourcesSubscribe:
+		return s.handleResourcesSubscribe(session, req.Params, true)
+	case MethodResourcesUnsubscribe:
+		return s.handleResourcesSubscribe(session, req.Params, false)
+	case MethodPromptsList:
+		return s.handlePromptsList()
+	case MethodPromptsGet:
+		return s.handlePromptsGet(session, req.Params)
+	case MethodCompletionComplete:
+		return s.handleComplete(session, req.Params)
+	default:
+		return nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+func (s *Server) handleInitialize(session *Session, params json.RawMessage) (interface{}, *Error) {
+	var initParams InitializeParams
+	if err := json.Unmarshal(params, &initParams); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid initialize params", Data: err.Error()}
+	}
+
+	session.mu.Lock()
+	session.ClientInfo = initParams.ClientInfo
+	session.ClientCapabilities = initParams.Capabilities
+	session.mu.Unlock()
+
+	log.Printf("🤝 MCP client connected: %s %s (protocol %s)", initParams.ClientInfo.Name, initParams.ClientInfo.Version, initParams.ProtocolVersion)
+
+	return InitializeResult{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities: ServerCapabilities{
+			Tools:       &ToolsCapability{ListChanged: false},
+			Resources:   &ResourcesCapability{Subscribe: true, ListChanged: true},
+			Prompts:     &PromptsCapability{ListChanged: false},
+			Completions: &struct{}{},
+		},
+		ServerInfo: Implementation{Name: ServerName, Version: ServerVersion},
+		Instructions: "Index GitHub repositories with index_repository, then query them with search_code " +
+			"or search_code_with_summary. Read the full file behind a search hit from its repo:// resource URI.",
+	}, nil
+}
+
+func (s *Server) handleToolsList() (interface{}, *Error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tools := make([]Tool, 0, len(s.toolOrder))
+	for _, name := range s.toolOrder {
+		tools = append(tools, s.tools[name].tool)
+	}
+	return ListToolsResult{Tools: tools}, nil
+}
+
+func (s *Server) handleToolsCall(ctx context.Context, session *Session, params json.RawMessage) (interface{}, *Error) {
+	var callParams CallToolParams
+	if err := json.Unmarshal(params, &callParams); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid tools/call params", Data: err.Error()}
+	}
+
+	s.mu.RLock()
+	registered, exists := s.tools[callParams.Name]
+	s.mu.RUnlock()
+	if !exists {
+		return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", callParams.Name)}
+	}
+
+	args := callParams.Arguments
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	if callParams.Meta != nil && len(callParams.Meta.ProgressToken) > 0 {
+		ctx = context.WithValue(ctx, progressTokenKey{}, callParams.Meta.ProgressToken)
+	}
+
+	log.Printf("🔧 MCP tool call: %s", callParams.Name)
+	result, err := registered.handler(ctx, session, args)
+	if err != nil {
+		// Tool failures are reported in the result so the model can see them
+		return CallToolResult{
+			Content: []Content{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, &Error{Code: CodeInternalError, Message: "failed to encode tool result", Data: err.Error()}
+	}
+
+	return CallToolResult{Content: []Content{{Type: "text", Text: string(text)}}}, nil
+}
+
+func (s *Server) handleCancelled(session *Session, params json.RawMessage) (interface{}, *Error) {
+	var cancelParams CancelledParams
+	if err := json.Unmarshal(params, &cancelParams); err != nil || len(cancelParams.RequestID) == 0 {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid notifications/cancelled params"}
+	}
+
+	// Unknown or already finished requests are ignored
+	if session.cancelRequest(cancelParams.RequestID) {
+		log.Printf("🛑 Cancelling MCP request %s: %s", string(cancelParams.RequestID), cancelParams.Reason)
+	}
+	return nil, nil
+}
+
+func errorResponse(id json.RawMessage, rpcErr *Error) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: JSONRPCVersion, ID: id, Error: rpcErr}
+}
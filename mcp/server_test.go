@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		wantIDs   []string // IDs of the expected responses, nil for no response
+		wantBatch bool
+		wantCode  int // Error code of the first response, 0 for success
+	}{
+		{name: "request", payload: `{"jsonrpc":"2.0","id":1,"method":"ping"}`, wantIDs: []string{"1"}},
+		{name: "string id", payload: `{"jsonrpc":"2.0","id":"a","method":"ping"}`, wantIDs: []string{`"a"`}},
+		{name: "notification", payload: `{"jsonrpc":"2.0","method":"notifications/initialized"}`},
+		{name: "unknown notification", payload: `{"jsonrpc":"2.0","method":"notifications/unknown"}`},
+		{name: "unknown method", payload: `{"jsonrpc":"2.0","id":1,"method":"unknown"}`, wantIDs: []string{"1"}, wantCode: CodeMethodNotFound},
+		{name: "invalid json", payload: `{"jsonrpc":`, wantIDs: []string{"null"}, wantCode: CodeParseError},
+		{name: "wrong version", payload: `{"jsonrpc":"1.0","id":1,"method":"ping"}`, wantIDs: []string{"1"}, wantCode: CodeInvalidRequest},
+		{name: "response to unknown request", payload: `{"jsonrpc":"2.0","id":"srv-1","result":{}}`},
+		{
+			name:      "batch of requests",
+			payload:   `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2,"method":"ping"}]`,
+			wantIDs:   []string{"1", "2"},
+			wantBatch: true,
+		},
+		{
+			name:      "batch mixing requests and notifications",
+			payload:   `[{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":7,"method":"ping"}]`,
+			wantIDs:   []string{"7"},
+			wantBatch: true,
+		},
+		{
+			name:    "batch of notifications",
+			payload: `[{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":9}}]`,
+		},
+		{
+			name:      "batch with invalid member",
+			payload:   `[1,{"jsonrpc":"2.0","id":2,"method":"ping"}]`,
+			wantIDs:   []string{"null", "2"},
+			wantBatch: true,
+			wantCode:  CodeParseError,
+		},
+		{name: "empty batch", payload: `[]`, wantIDs: []string{"null"}, wantCode: CodeInvalidRequest},
+		{name: "invalid batch", payload: `[{"jsonrpc":"2.0"`, wantIDs: []string{"null"}, wantCode: CodeParseError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer()
+			session := s.CreateSession("user")
+			result := s.Handle(context.Background(), session, []byte(tt.payload))
+
+			if tt.wantIDs == nil {
+				if result != nil {
+					t.Fatalf("Handle() = %+v, want no response", result)
+				}
+				return
+			}
+
+			var responses []*Response
+			switch r := result.(type) {
+			case *Response:
+				if tt.wantBatch {
+					t.Fatalf("Handle() returned a single response, want a batch")
+				}
+				responses = []*Response{r}
+			case []*Response:
+				if !tt.wantBatch {
+					t.Fatalf("Handle() returned a batch, want a single response")
+				}
+				responses = r
+			default:
+				t.Fatalf("Handle() = %#v, want responses", result)
+			}
+
+			if len(responses) != len(tt.wantIDs) {
+				t.Fatalf("Handle() returned %d responses, want %d", len(responses), len(tt.wantIDs))
+			}
+			for i, resp := range responses {
+				id := string(resp.ID)
+				if id == "" {
+					id = "null"
+				}
+				if id != tt.wantIDs[i] {
+					t.Errorf("response %d has ID %s, want %s", i, id, tt.wantIDs[i])
+				}
+			}
+
+			first := responses[0]
+			switch {
+			case tt.wantCode == 0 && first.Error != nil:
+				t.Errorf("unexpected error: %+v", first.Error)
+			case tt.wantCode != 0 && (first.Error == nil || first.Error.Code != tt.wantCode):
+				t.Errorf("error = %+v, want code %d", first.Error, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestInspectPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    PayloadInfo
+		wantErr bool
+	}{
+		{name: "request", payload: `{"jsonrpc":"2.0","id":1,"method":"ping"}`, want: PayloadInfo{HasRequests: true}},
+		{name: "initialize", payload: `{"jsonrpc":"2.0","id":1,"method":"initialize"}`, want: PayloadInfo{HasRequests: true, HasInitialize: true}},
+		{name: "notification", payload: `{"jsonrpc":"2.0","method":"notifications/initialized"}`},
+		{name: "response", payload: `{"jsonrpc":"2.0","id":"srv-1","result":{}}`},
+		{name: "batch with a request", payload: `[{"jsonrpc":"2.0","method":"notifications/initialized"},{"jsonrpc":"2.0","id":2,"method":"ping"}]`, want: PayloadInfo{HasRequests: true}},
+		{name: "batch of notifications", payload: `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`},
+		{name: "invalid json", payload: `{"jsonrpc":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InspectPayload([]byte(tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InspectPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("InspectPayload() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsToolCall(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    bool
+	}{
+		{name: "tool call", payload: `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search_code"}}`, want: true},
+		{name: "tool call notification", payload: `{"jsonrpc":"2.0","method":"tools/call"}`},
+		{name: "initialize", payload: `{"jsonrpc":"2.0","id":1,"method":"initialize"}`},
+		{name: "notification", payload: `{"jsonrpc":"2.0","method":"notifications/initialized"}`},
+		{name: "response", payload: `{"jsonrpc":"2.0","id":"srv-1","result":{}}`},
+		{name: "batch", payload: `[{"jsonrpc":"2.0","id":1,"method":"tools/call"}]`},
+		{name: "invalid json", payload: `{"jsonrpc":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsToolCall([]byte(tt.payload)); got != tt.want {
+				t.Errorf("IsToolCall() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
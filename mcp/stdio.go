@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+)
+
+// StdioUserID is the identity used for tool calls made over stdio, where
+// the client is the local process owner
+const StdioUserID = "stdio"
+
+// maxMessageSize bounds a single newline-delimited JSON-RPC message
+const maxMessageSize = 10 * 1024 * 1024
+
+// ServeStdio serves MCP over newline-delimited JSON-RPC on the given reader
+// and writer until the reader is closed or the context is cancelled
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var writeMu sync.Mutex
+	encoder := json.NewEncoder(out)
+	write := func(v interface{}) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return encoder.Encode(v)
+	}
+
+	reply := func(resp interface{}) {
+		if resp == nil {
+			return
+		}
+		if err := write(resp); err != nil {
+			log.Printf("⚠️  Failed to write MCP response: %v", err)
+		}
+	}
+
+	session := s.CreateSession(StdioUserID)
+	session.Local = true
+	session.SetSender(write)
+	defer s.CloseSession(session.ID)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	log.Printf("🔌 MCP stdio transport ready")
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		// Copy the line since the scanner reuses its buffer
+		data := append([]byte(nil), line...)
+
+		// Tool calls run concurrently so they don't block pings,
+		// cancellations or the client's answers to sampling requests.
+		// Everything else is handled in arrival order.
+		if !IsToolCall(data) {
+			reply(s.Handle(ctx, session, data))
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply(s.Handle(ctx, session, data))
+		}()
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	log.Printf("🔌 MCP stdio client disconnected")
+	return nil
+}
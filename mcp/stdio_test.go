@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestServeStdioAnswersInOrder(t *testing.T) {
+	var in strings.Builder
+	in.WriteString(`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}` + "\n")
+	in.WriteString(`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n")
+	for i := 1; i <= 50; i++ {
+		fmt.Fprintf(&in, `{"jsonrpc":"2.0","id":%d,"method":"ping"}`+"\n", i)
+	}
+
+	var out bytes.Buffer
+	if err := NewServer().ServeStdio(context.Background(), strings.NewReader(in.String()), &out); err != nil {
+		t.Fatalf("ServeStdio() error = %v", err)
+	}
+
+	scanner := bufio.NewScanner(&out)
+	want := 0
+	for scanner.Scan() {
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", scanner.Text(), err)
+		}
+		if resp.Error != nil {
+			t.Fatalf("response %s failed: %+v", string(resp.ID), resp.Error)
+		}
+		if got := string(resp.ID); got != fmt.Sprint(want) {
+			t.Fatalf("response has ID %s, want %d", got, want)
+		}
+		want++
+	}
+	if want != 51 {
+		t.Errorf("got %d responses, want 51", want)
+	}
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+)
+
+func (s *Server) handleResourcesSubscribe(session *Session, params json.RawMessage, subscribe bool) (interface{}, *Error) {
+	var subscribeParams SubscribeParams
+	if err := json.Unmarshal(params, &subscribeParams); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: "invalid subscription params", Data: err.Error()}
+	}
+
+	// Both file URIs and branch URIs (repo://owner/name@branch) may be
+	// subscribed; the resource does not have to be indexed yet
+	if _, _, _, err := parseResourceURI(subscribeParams.URI); err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	session.mu.Lock()
+	if subscribe {
+		if session.subscriptions == nil {
+			session.subscriptions = make(map[string]bool)
+		}
+		session.subscriptions[subscribeParams.URI] = true
+	} else {
+		delete(session.subscriptions, subscribeParams.URI)
+	}
+	session.mu.Unlock()
+
+	return struct{}{}, nil
+}
+
+// subscribedURIs returns the subscriptions of a session that belong to the
+// given repository branch
+func (sess *Session) subscribedURIs(repository, branch string) []string {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	var uris []string
+	for uri := range sess.subscriptions {
+		subRepository, subBranch, _, err := parseResourceURI(uri)
+		if err == nil && subRepository == repository && subBranch == branch {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// notifyRepositoryIndexed tells every session that the resource list changed
+// and subscribers of the repository branch that their resources were updated
+func (s *Server) notifyRepositoryIndexed(repository, branch string) {
+	s.sessionsMu.RLock()
+	sessions := make([]*Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessionsMu.RUnlock()
+
+	ctx := context.Background()
+	for _, session := range sessions {
+		// Sessions without an open stream simply miss the notification
+		session.Notify(ctx, MethodResourcesListChanged, nil)
+
+		for _, uri := range session.subscribedURIs(repository, branch) {
+			if err := session.Notify(ctx, MethodResourcesUpdated, ResourceUpdatedParams{URI: uri}); err != nil {
+				log.Printf("⚠️  Failed to notify session %s about %s: %v", session.ID, uri, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mcp-go-server/models"
+	"mcp-go-server/usecase"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Tool names exposed over MCP
+const (
+	ToolSearchCode            = "search_code"
+	ToolSearchCodeWithSummary = "search_code_with_summary"
+	ToolIndexRepository       = "index_repository"
+	ToolListRepositories      = "list_repositories"
+)
+
+// registerSearchTools registers the code search and indexing tools
+func registerSearchTools(s *Server) {
+	s.AddTool(Tool{
+		Name:        ToolSearchCode,
+		Description: "Semantic vector search over the code of an indexed repository. Returns the most relevant code chunks.",
+		InputSchema: SchemaFor(models.SearchRequest{}),
+	}, searchCodeTool)
+
+	s.AddTool(Tool{
+		Name:        ToolSearchCodeWithSummary,
+		Description: "Semantic vector search over an indexed repository followed by an AI-generated summary answering the query. With summary_mode 'client' the summary is generated by your own model via sampling.",
+		InputSchema: SchemaFor(models.SearchRequest{}),
+	}, searchCodeWithSummaryTool)
+
+	s.AddTool(Tool{
+		Name:        ToolIndexRepository,
+		Description: "Clone a git repository and index its files for vector search. This may take several minutes for large repositories.",
+		InputSchema: SchemaFor(models.IndexRequest{}),
+	}, indexRepositoryTool)
+
+	s.AddTool(Tool{
+		Name:        ToolListRepositories,
+		Description: "List the repositories indexed by the current user.",
+		InputSchema: SchemaFor(struct{}{}),
+	}, listRepositoriesTool)
+}
+
+// searchToolResult adds the resource URIs of the matched files to a search
+// response so clients can read the whole file behind a hit
+type searchToolResult struct {
+	models.SearchResponse
+	Resources []string `json:"resources"`
+}
+
+// searchSummaryToolResult is the summary counterpart of searchToolResult
+type searchSummaryToolResult struct {
+	models.SearchWithSummaryResponse
+	Resources []string `json:"resources"`
+}
+
+// resourceURIs returns the unique file URIs referenced by search results
+func resourceURIs(results []models.SearchResult) []string {
+	seen := make(map[string]bool)
+	uris := []string{}
+	for _, result := range results {
+		uri := FileURI(result.Repository, result.Branch, result.FilePath)
+		if !seen[uri] {
+			seen[uri] = true
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// decodeArguments unmarshals and validates tool arguments. Unknown fields
+// are rejected, matching the additionalProperties:false of the input
+// schemas, so misspelled arguments fail instead of being ignored.
+func decodeArguments(args json.RawMessage, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(args))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	if decoder.More() {
+		return errors.New("invalid arguments: trailing data after the arguments object")
+	}
+	if err := validator.New().Struct(v); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return nil
+}
+
+func searchCodeTool(ctx context.Context, session *Session, args json.RawMessage) (interface{}, error) {
+	var searchReq models.SearchRequest
+	if err := decodeArguments(args, &searchReq); err != nil {
+		return nil, err
+	}
+
+	// Set default values
+	if searchReq.Branch == "" {
+		searchReq.Branch = usecase.DefaultBranch(session.UserID, searchReq.Repository)
+	}
+	if searchReq.Limit <= 0 {
+		searchReq.Limit = 10
+	}
+
+	searchResponse, err := usecase.PerformVectorSearch(session.UserID, searchReq)
+	if err != nil {
+		return nil, err
+	}
+	return searchToolResult{SearchResponse: searchResponse, Resources: resourceURIs(searchResponse.Results)}, nil
+}
+
+func searchCodeWithSummaryTool(ctx context.Context, session *Session, args json.RawMessage) (interface{}, error) {
+	var searchReq models.SearchRequest
+	if err := decodeArguments(args, &searchReq); err != nil {
+		return nil, err
+	}
+
+	// Set default values
+	if searchReq.Branch == "" {
+		searchReq.Branch = usecase.DefaultBranch(session.UserID, searchReq.Repository)
+	}
+	if searchReq.Limit <= 0 {
+		searchReq.Limit = 5
+	}
+
+	summaryResponse, err := usecase.PerformSearchWithClientSummary(ctx, session.UserID, searchReq, samplingSummarizer(session))
+	if err != nil {
+		return nil, err
+	}
+	return searchSummaryToolResult{SearchWithSummaryResponse: summaryResponse, Resources: resourceURIs(summaryResponse.Results)}, nil
+}
+
+func indexRepositoryTool(ctx context.Context, session *Session, args json.RawMessage) (interface{}, error) {
+	var indexReq models.IndexRequest
+	if err := decodeArguments(args, &indexReq); err != nil {
+		return nil, err
+	}
+
+	return usecase.IndexRepositoryForUser(ctx, session.UserID, indexReq, newIndexProgressReporter(ctx, session))
+}
+
+// newIndexProgressReporter forwards indexing progress as MCP progress
+// notifications. Progress counts every started file and stored chunk so it
+// strictly increases, also across consecutive indexing runs; the total is
+// unknown until the files have been split.
+func newIndexProgressReporter(ctx context.Context, session *Session) func(models.IndexProgress) {
+	offset, lastRaw, lastProgress := 0.0, 0.0, -1.0
+	return func(progress models.IndexProgress) {
+		raw := float64(progress.CurrentFile + progress.CurrentChunk)
+		if raw < lastRaw {
+			// A new indexing run started counting from zero
+			offset += lastRaw
+		}
+		lastRaw = raw
+
+		value := offset + raw
+		if value <= lastProgress {
+			return
+		}
+		lastProgress = value
+
+		message := progress.Message
+		if progress.TotalFiles > 0 {
+			message = fmt.Sprintf("[file %d/%d, %d chunks] %s", progress.CurrentFile, progress.TotalFiles, progress.CurrentChunk, progress.Message)
+		}
+		if err := session.NotifyProgress(ctx, value, 0, message); err != nil {
+			log.Printf("⚠️  Failed to send MCP progress: %v", err)
+		}
+	}
+}
+
+func listRepositoriesTool(ctx context.Context, session *Session, args json.RawMessage) (interface{}, error) {
+	repositories, err := usecase.GetRepositories(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if repositories == nil {
+		repositories = []models.RepositoryInfo{}
+	}
+	return repositories, nil
+}
@@ -0,0 +1,32 @@
+package mcp
+
+import (
+	"encoding/json"
+	"mcp-go-server/models"
+	"testing"
+)
+
+func TestDecodeArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		wantErr bool
+	}{
+		{name: "valid", args: `{"query":"parse config","repository":"acme/api","limit":5}`},
+		{name: "unknown field", args: `{"query":"parse config","repository":"acme/api","limt":5}`, wantErr: true},
+		{name: "missing required field", args: `{"query":"parse config"}`, wantErr: true},
+		{name: "wrong type", args: `{"query":"parse config","repository":"acme/api","limit":"5"}`, wantErr: true},
+		{name: "trailing data", args: `{"query":"parse config","repository":"acme/api"} {}`, wantErr: true},
+		{name: "invalid summary mode", args: `{"query":"q","repository":"acme/api","summary_mode":"remote"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req models.SearchRequest
+			err := decodeArguments(json.RawMessage(tt.args), &req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeArguments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware validates JWT tokens
+// AuthMiddleware validates JWT tokens and static service tokens
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -38,6 +38,13 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Service tokens let headless clients authenticate without OAuth
+		if serviceUserID, ok := helper.ValidateServiceToken(token); ok {
+			c.Set(models.UserIDKey, serviceUserID)
+			c.Next()
+			return
+		}
+
 		// Validate and parse token
 		userID, err := helper.ValidateJWTToken(token)
 		if err != nil {
@@ -53,6 +60,20 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// MCPOriginMiddleware rejects MCP requests from browser origins that are
+// not allowed, as the Streamable HTTP transport requires
+func MCPOriginMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !helper.IsAllowedOrigin(c.GetHeader("Origin")) {
+			errRes := response.ErrorClientResponse(http.StatusForbidden, "Origin not allowed", nil)
+			c.JSON(http.StatusForbidden, errRes)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // CORSMiddleware handles Cross-Origin Resource Sharing
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
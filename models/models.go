@@ -5,6 +5,12 @@ import "errors"
 // Constants
 const UserIDKey = "user_id"
 
+// LocalWorkspaceBranch is the branch label used for indexed local directories
+const LocalWorkspaceBranch = "workspace"
+
+// MaxUploadSize limits the size of uploaded repository archives
+const MaxUploadSize = 100 << 20
+
 // Custom errors
 var (
 	ErrEmailNotFound        = errors.New("email not found")
@@ -12,6 +18,14 @@ var (
 	ErrUserNotAuthenticated = errors.New("user not authenticated")
 	ErrRepositoryNotFound   = errors.New("repository not found")
 	ErrInvalidBranch        = errors.New("invalid branch")
+	ErrFileNotFound         = errors.New("file not found")
+	ErrIndexingCancelled    = errors.New("indexing cancelled")
+	ErrSummaryModeInvalid   = errors.New("client summary mode requires an MCP client that supports sampling")
+	ErrJobNotFound          = errors.New("indexing job not found")
+	ErrIndexingInProgress   = errors.New("repository branch is already being indexed")
+	ErrRefNotFound          = errors.New("branch or tag not found in remote repository")
+	ErrInvalidArchive       = errors.New("invalid or unsafe archive")
+	ErrRepositoryForbidden  = errors.New("repository was indexed by another user")
 )
 
 // Auth models
@@ -31,10 +45,14 @@ type UserResponse struct {
 
 // Search models
 type SearchRequest struct {
-	Query      string `json:"query" validate:"required,min=1"`
-	Repository string `json:"repository" validate:"required"`
-	Branch     string `json:"branch"`
-	Limit      int    `json:"limit"`
+	Query      string `json:"query" validate:"required,min=1" description:"Natural language or code search query"`
+	Repository string `json:"repository" validate:"required" description:"Indexed repository in owner/name format"`
+	Branch     string `json:"branch" description:"Indexed branch (defaults to the most recently indexed branch)"`
+	Limit      int    `json:"limit" description:"Maximum number of results to return"`
+	// SummaryMode selects who generates summaries: "server" uses OpenAI,
+	// "client" asks the connected MCP client's model via sampling. Servers
+	// configured for client summaries ignore "server".
+	SummaryMode string `json:"summary_mode,omitempty" validate:"omitempty,oneof=server client" description:"Summary generation: 'server' (OpenAI, unless the server only allows client summaries) or 'client' (MCP sampling)"`
 }
 
 type SearchResponse struct {
@@ -48,9 +66,21 @@ type SearchResult struct {
 	Repository string  `json:"repository"`
 	Branch     string  `json:"branch"`
 	Language   string  `json:"language"`
+	StartLine  int     `json:"start_line,omitempty"`
+	EndLine    int     `json:"end_line,omitempty"`
+	CommitSHA  string  `json:"commit_sha,omitempty"`
+	Permalink  string  `json:"permalink,omitempty"`
 	Score      float32 `json:"score"`
 }
 
+// SummaryPrompt is the model input used to summarize search results
+type SummaryPrompt struct {
+	SystemPrompt string  `json:"system_prompt"`
+	UserPrompt   string  `json:"user_prompt"`
+	Temperature  float32 `json:"temperature"`
+	MaxTokens    int     `json:"max_tokens"`
+}
+
 type SearchWithSummaryResponse struct {
 	Summary string         `json:"summary"`
 	Results []SearchResult `json:"results"`
@@ -59,22 +89,50 @@ type SearchWithSummaryResponse struct {
 
 // Repository indexing models
 type IndexRequest struct {
-	RepoURL string `json:"repo_url" validate:"required,url"`
-	Branch  string `json:"branch"`
+	RepoURL       string   `json:"repo_url" validate:"required" description:"Git repository URL: GitHub, GitLab (with subgroups), Bitbucket, Gitea or any https:// or ssh:// remote"`
+	Branch        string   `json:"branch" description:"Branch or tag to index (defaults to the repository's default branch)"`
+	Full          bool     `json:"full,omitempty" description:"Re-embed every file instead of only the files changed since the last indexed commit"`
+	Concurrency   int      `json:"concurrency,omitempty" validate:"omitempty,min=1,max=32" description:"Number of files processed in parallel (defaults to the server setting)"`
+	ChunkStrategy string   `json:"chunk_strategy,omitempty" validate:"omitempty,oneof=auto lines bytes" description:"Chunking strategy: auto (language-aware, e.g. Go declarations), lines or bytes sliding windows (defaults to auto)"`
+	ChunkSize     int      `json:"chunk_size,omitempty" validate:"omitempty,min=1" description:"Window size in lines for the lines strategy, in bytes otherwise (defaults to 40 lines or 1000 bytes)"`
+	ChunkOverlap  int      `json:"chunk_overlap,omitempty" validate:"omitempty,min=0" description:"Lines or bytes shared by consecutive windows, smaller than chunk_size (defaults to 5 lines or 200 bytes)"`
+	Include       []string `json:"include,omitempty" description:"Only index files matching these globs, e.g. *.go or cmd/**"`
+	Exclude       []string `json:"exclude,omitempty" description:"Skip files matching these globs, e.g. vendor/** or *_test.go"`
+}
+
+// UploadIndexRequest describes an uploaded repository snapshot. The fields
+// are sent as multipart form values next to the archive file.
+type UploadIndexRequest struct {
+	Repository string `form:"repository" validate:"required" description:"Name to index the archive under, e.g. firmware; stored as upload/<user>/<name>"`
+	Ref        string `form:"ref" validate:"required" description:"Branch, tag or other ref label of the snapshot"`
 }
 
 type IndexResponse struct {
-	Repository string `json:"repository"`
-	Branch     string `json:"branch"`
-	FileCount  int    `json:"file_count"`
-	ChunkCount int    `json:"chunk_count"`
-	Status     string `json:"status"`
+	Repository   string         `json:"repository"`
+	Branch       string         `json:"branch"`
+	FileCount    int            `json:"file_count"`
+	ChunkCount   int            `json:"chunk_count"`
+	Status       string         `json:"status"`
+	CommitSHA    string         `json:"commit_sha,omitempty"`
+	Incremental  bool           `json:"incremental"`
+	Private      bool           `json:"private,omitempty"`
+	RemovedFiles int            `json:"removed_files,omitempty"`
+	FailedChunks int            `json:"failed_chunks,omitempty"`
+	Failures     []BatchFailure `json:"failures,omitempty"`
+}
+
+// BatchFailure describes an embedding or upsert batch that could not be stored
+type BatchFailure struct {
+	Stage  string   `json:"stage"` // "embedding" or "upsert"
+	Chunks int      `json:"chunks"`
+	Files  []string `json:"files"`
+	Error  string   `json:"error"`
 }
 
 type IndexProgress struct {
 	Repository   string `json:"repository"`
 	Branch       string `json:"branch"`
-	Status       string `json:"status"` // "processing", "completed", "failed"
+	Status       string `json:"status"` // "queued", "cloning", "processing", "completed", "failed", "cancelled"
 	CurrentFile  int    `json:"current_file"`
 	TotalFiles   int    `json:"total_files"`
 	CurrentChunk int    `json:"current_chunk"`
@@ -84,6 +142,17 @@ type IndexProgress struct {
 	EndTime      string `json:"end_time,omitempty"`
 }
 
+// IndexJob is a background indexing run
+type IndexJob struct {
+	ID       string         `json:"id"`
+	UserID   string         `json:"-"`
+	RepoURL  string         `json:"repo_url"`
+	Branch   string         `json:"branch"`
+	Progress IndexProgress  `json:"progress"`
+	Result   *IndexResponse `json:"result,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
 type RepositoryInfo struct {
 	Name       string `json:"name"`
 	Owner      string `json:"owner"`
@@ -92,6 +161,14 @@ type RepositoryInfo struct {
 	IndexedAt  string `json:"indexed_at"`
 	FileCount  int    `json:"file_count"`
 	ChunkCount int    `json:"chunk_count"`
+	CommitSHA  string `json:"commit_sha,omitempty"`
+}
+
+// DeleteRepositoryResponse reports the vectors removed with a repository
+type DeleteRepositoryResponse struct {
+	Repository     string   `json:"repository"`
+	Branches       []string `json:"branches"`
+	VectorsRemoved int      `json:"vectors_removed"`
 }
 
 // Code chunk model
@@ -0,0 +1,383 @@
+package repository
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mcp-go-server/models"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Archive formats accepted for uploaded repository snapshots
+const (
+	ArchiveTarGz = "tar.gz"
+	ArchiveZip   = "zip"
+)
+
+// Extraction limits guarding against archive bombs
+const (
+	maxArchiveEntries = 100000
+	maxExtractedBytes = 1 << 30 // 1 GiB
+	maxSymlinkTarget  = 4096
+)
+
+// ArchiveFormat returns the format of an uploaded archive from its file name
+func ArchiveFormat(filename string) (string, error) {
+	name := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ArchiveTarGz, nil
+	case strings.HasSuffix(name, ".zip"):
+		return ArchiveZip, nil
+	default:
+		return "", fmt.Errorf("%w: only .tar.gz, .tgz and .zip archives are supported", models.ErrInvalidArchive)
+	}
+}
+
+// ExtractArchive safely extracts a .tar.gz or .zip archive into a temporary
+// directory. It returns the directory holding the files, which is the
+// archive's single top-level directory if it has one, and a function that
+// removes the extracted files. Entries escaping the root, symlinks pointing
+// outside it and archives exceeding the size or entry limits are rejected
+// with models.ErrInvalidArchive.
+func ExtractArchive(archive io.ReaderAt, size int64, format string) (string, func(), error) {
+	tempDir, err := ioutil.TempDir("", "upload-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+
+	x := &extractor{root: tempDir}
+	switch format {
+	case ArchiveTarGz:
+		err = x.extractTarGz(io.NewSectionReader(archive, 0, size))
+	case ArchiveZip:
+		err = x.extractZip(archive, size)
+	default:
+		err = fmt.Errorf("%w: unsupported archive format %q", models.ErrInvalidArchive, format)
+	}
+	if err == nil {
+		err = x.createSymlinks()
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	log.Printf("📦 Extracted %d archive entries (%d bytes) to %s", x.entries, x.written, tempDir)
+
+	// Archives of a project usually wrap it in a single directory
+	repoPath := tempDir
+	if entries, err := ioutil.ReadDir(tempDir); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		repoPath = filepath.Join(tempDir, entries[0].Name())
+	}
+	return repoPath, cleanup, nil
+}
+
+// extractor writes archive entries below root. Symlinks are created only
+// after every file is written, so no file is ever written through a link.
+type extractor struct {
+	root     string
+	entries  int
+	written  int64
+	symlinks []pendingSymlink
+}
+
+type pendingSymlink struct {
+	path   string
+	target string
+}
+
+func (x *extractor) extractTarGz(r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+		}
+		if err := x.countEntry(); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = x.mkdir(header.Name)
+		case tar.TypeReg:
+			err = x.writeFile(header.Name, tr)
+		case tar.TypeSymlink:
+			err = x.addSymlink(header.Name, header.Linkname)
+		case tar.TypeLink:
+			err = x.addHardlink(header.Name, header.Linkname)
+		default:
+			continue // Devices, FIFOs and other special files are skipped
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (x *extractor) extractZip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+
+	for _, file := range zr.File {
+		if err := x.countEntry(); err != nil {
+			return err
+		}
+
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.mkdir(file.Name)
+		case mode&os.ModeSymlink != 0:
+			var target []byte
+			target, err = readZipFile(file, maxSymlinkTarget)
+			if err == nil {
+				err = x.addSymlink(file.Name, string(target))
+			}
+		case mode.IsRegular():
+			var rc io.ReadCloser
+			if rc, err = file.Open(); err != nil {
+				return fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+			}
+			err = x.writeFile(file.Name, rc)
+			rc.Close()
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readZipFile reads a small zip entry such as a symlink target
+func readZipFile(file *zip.File, limit int64) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+	defer rc.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("%w: symlink target of %s is too long", models.ErrInvalidArchive, file.Name)
+	}
+	return content, nil
+}
+
+// countEntry enforces the entry limit
+func (x *extractor) countEntry() error {
+	x.entries++
+	if x.entries > maxArchiveEntries {
+		return fmt.Errorf("%w: more than %d entries", models.ErrInvalidArchive, maxArchiveEntries)
+	}
+	return nil
+}
+
+// target maps an entry name to its path below root, rejecting absolute
+// names and names with ".." segments
+func (x *extractor) target(name string) (string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if path.IsAbs(name) {
+		return "", fmt.Errorf("%w: absolute path %q", models.ErrInvalidArchive, name)
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("%w: path %q escapes the archive root", models.ErrInvalidArchive, name)
+		}
+	}
+	return filepath.Join(x.root, filepath.FromSlash(path.Clean(name))), nil
+}
+
+func (x *extractor) mkdir(name string) error {
+	target, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(target, 0o755)
+}
+
+func (x *extractor) writeFile(name string, r io.Reader) error {
+	target, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	if target == x.root {
+		return fmt.Errorf("%w: invalid file name %q", models.ErrInvalidArchive, name)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Read one byte past the remaining budget to detect oversized archives
+	remaining := maxExtractedBytes - x.written
+	n, err := io.Copy(file, io.LimitReader(r, remaining+1))
+	x.written += n
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+	if x.written > maxExtractedBytes {
+		return fmt.Errorf("%w: archive expands to more than %d bytes", models.ErrInvalidArchive, int64(maxExtractedBytes))
+	}
+	return nil
+}
+
+// addHardlink links name to a regular file extracted earlier
+func (x *extractor) addHardlink(name, linkname string) error {
+	target, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	source, err := x.target(linkname)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Lstat(source); err != nil || !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: hard link %q does not point to a regular file", models.ErrInvalidArchive, name)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.Link(source, target)
+}
+
+// addSymlink queues a symlink whose target must stay inside the root
+func (x *extractor) addSymlink(name, linkname string) error {
+	target, err := x.target(name)
+	if err != nil {
+		return err
+	}
+	if filepath.IsAbs(linkname) || !withinDir(x.root, filepath.Join(filepath.Dir(target), linkname)) {
+		return fmt.Errorf("%w: symlink %q points outside the archive", models.ErrInvalidArchive, name)
+	}
+	x.symlinks = append(x.symlinks, pendingSymlink{path: target, target: linkname})
+	return nil
+}
+
+// createSymlinks creates the queued symlinks, shallowest first, and verifies
+// that none of them resolves outside the root through another link. A link
+// is only created at a path of real directories below the root, so nothing
+// is ever created through an earlier link. Dangling links are removed.
+func (x *extractor) createSymlinks() error {
+	root, err := filepath.EvalSymlinks(x.root)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(x.symlinks, func(i, j int) bool {
+		return pathDepth(x.symlinks[i].path) < pathDepth(x.symlinks[j].path)
+	})
+	for _, link := range x.symlinks {
+		name := filepath.ToSlash(link.path[len(x.root)+1:])
+		parent := filepath.Dir(link.path)
+		if x.throughSymlink(parent) || x.targetThroughSymlink(parent, link.target) {
+			return fmt.Errorf("%w: symlink %q is nested in another symlink", models.ErrInvalidArchive, name)
+		}
+		if err := os.MkdirAll(parent, 0o755); err != nil {
+			return err
+		}
+		if resolved, err := filepath.EvalSymlinks(parent); err != nil || !withinDir(root, resolved) {
+			return fmt.Errorf("%w: symlink %q points outside the archive", models.ErrInvalidArchive, name)
+		}
+		if err := os.Symlink(link.target, link.path); err != nil {
+			return fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+		}
+	}
+
+	for _, link := range x.symlinks {
+		resolved, err := filepath.EvalSymlinks(link.path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				os.Remove(link.path)
+				continue
+			}
+			return fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+		}
+		if !withinDir(root, resolved) {
+			return fmt.Errorf("%w: symlink %q points outside the archive", models.ErrInvalidArchive, link.path[len(x.root)+1:])
+		}
+	}
+	return nil
+}
+
+// throughSymlink reports whether an existing component of dir below the
+// root is a symlink
+func (x *extractor) throughSymlink(dir string) bool {
+	rel, err := filepath.Rel(x.root, dir)
+	if err != nil || rel == "." {
+		return err != nil
+	}
+
+	current := x.root
+	for _, segment := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, segment)
+		info, err := os.Lstat(current)
+		if err != nil {
+			return false // Missing directories are created as real ones
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// targetThroughSymlink reports whether the directories a symlink target
+// passes through from dir include an existing symlink, which would make the
+// lexical target check meaningless
+func (x *extractor) targetThroughSymlink(dir, target string) bool {
+	segments := strings.Split(filepath.ToSlash(target), "/")
+	current := dir
+	for _, segment := range segments[:len(segments)-1] {
+		current = filepath.Join(current, segment)
+		if segment == ".." || segment == "." || segment == "" {
+			continue
+		}
+		if info, err := os.Lstat(current); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// pathDepth returns the number of separators in path
+func pathDepth(path string) int {
+	return strings.Count(path, string(filepath.Separator))
+}
+
+// withinDir reports whether path is dir or lies below it
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"mcp-go-server/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tarEntry describes an entry of a test archive
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+}
+
+// buildTarGz builds a .tar.gz archive from entries
+func buildTarGz(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Typeflag: entry.typeflag, Linkname: entry.linkname, Mode: 0o644}
+		switch entry.typeflag {
+		case tar.TypeReg:
+			header.Size = int64(len(entry.body))
+		case tar.TypeDir:
+			header.Mode = 0o755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("failed to write header of %s: %v", entry.name, err)
+		}
+		if entry.typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(entry.body)); err != nil {
+				t.Fatalf("failed to write %s: %v", entry.name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// buildZip builds a .zip archive from entries. Symlinks store their target
+// as the entry's content.
+func buildZip(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		body := entry.body
+		switch entry.typeflag {
+		case tar.TypeSymlink:
+			header.SetMode(os.ModeSymlink | 0o777)
+			body = entry.linkname
+		case tar.TypeDir:
+			header.Name = strings.TrimSuffix(entry.name, "/") + "/"
+			header.SetMode(os.ModeDir | 0o755)
+		default:
+			header.SetMode(0o644)
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("failed to write header of %s: %v", entry.name, err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatalf("failed to write %s: %v", entry.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// extractTestArchive extracts entries in the given format into a temporary
+// directory below tmpDir
+func extractTestArchive(t *testing.T, tmpDir, format string, entries []tarEntry) (string, func(), error) {
+	t.Helper()
+	t.Setenv("TMPDIR", tmpDir)
+
+	archive := buildTarGz(t, entries)
+	if format == ArchiveZip {
+		archive = buildZip(t, entries)
+	}
+	return ExtractArchive(bytes.NewReader(archive), int64(len(archive)), format)
+}
+
+func TestExtractArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats []string
+		entries []tarEntry
+		files   map[string]string // Expected file contents below the returned directory
+		wantErr bool
+	}{
+		{
+			name:    "strips single top-level directory",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{
+				{name: "project", typeflag: tar.TypeDir},
+				{name: "project/main.go", typeflag: tar.TypeReg, body: "package main"},
+				{name: "project/pkg/util.go", typeflag: tar.TypeReg, body: "package pkg"},
+			},
+			files: map[string]string{"main.go": "package main", "pkg/util.go": "package pkg"},
+		},
+		{
+			name:    "keeps several top-level entries",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{
+				{name: "a.go", typeflag: tar.TypeReg, body: "a"},
+				{name: "b/b.go", typeflag: tar.TypeReg, body: "b"},
+			},
+			files: map[string]string{"a.go": "a", "b/b.go": "b"},
+		},
+		{
+			name:    "follows symlink chains inside the root",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{
+				{name: "src/real.go", typeflag: tar.TypeReg, body: "real"},
+				{name: "first", typeflag: tar.TypeSymlink, linkname: "second"},
+				{name: "second", typeflag: tar.TypeSymlink, linkname: "src/real.go"},
+			},
+			files: map[string]string{"first": "real", "second": "real", "src/real.go": "real"},
+		},
+		{
+			name:    "removes dangling symlinks",
+			formats: []string{ArchiveTarGz},
+			entries: []tarEntry{
+				{name: "a.go", typeflag: tar.TypeReg, body: "a"},
+				{name: "missing", typeflag: tar.TypeSymlink, linkname: "nowhere"},
+			},
+			files: map[string]string{"a.go": "a"},
+		},
+		{
+			name:    "links hard links to earlier files",
+			formats: []string{ArchiveTarGz},
+			entries: []tarEntry{
+				{name: "a.go", typeflag: tar.TypeReg, body: "a"},
+				{name: "b.go", typeflag: tar.TypeLink, linkname: "a.go"},
+			},
+			files: map[string]string{"a.go": "a", "b.go": "a"},
+		},
+		{
+			name:    "rejects parent directory traversal",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{{name: "../escaped-poc", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "rejects nested traversal",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{{name: "a/../../escaped-poc", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "rejects absolute paths",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{{name: "/tmp/escaped-poc", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "rejects backslash traversal",
+			formats: []string{ArchiveZip},
+			entries: []tarEntry{{name: `..\escaped-poc`, typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "rejects symlinks pointing outside",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{{name: "escaped-poc", typeflag: tar.TypeSymlink, linkname: "../outside"}},
+			wantErr: true,
+		},
+		{
+			name:    "rejects absolute symlinks",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{{name: "passwd", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}},
+			wantErr: true,
+		},
+		{
+			// s/u resolves to the root, so t resolves to the root's parent
+			// and a link created below t would land outside the root
+			name:    "rejects symlinks created through symlinks",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{
+				{name: "s/u", typeflag: tar.TypeSymlink, linkname: ".."},
+				{name: "t", typeflag: tar.TypeSymlink, linkname: "s/u/.."},
+				{name: "t/escaped-poc/x", typeflag: tar.TypeSymlink, linkname: "y"},
+			},
+			wantErr: true,
+		},
+		{
+			// Lexically a/b/.. is a, but a/b resolves to the root
+			name:    "rejects symlink targets through symlinks",
+			formats: []string{ArchiveTarGz, ArchiveZip},
+			entries: []tarEntry{
+				{name: "a/b", typeflag: tar.TypeSymlink, linkname: ".."},
+				{name: "escaped-poc", typeflag: tar.TypeSymlink, linkname: "a/b/.."},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "rejects hard links to missing files",
+			formats: []string{ArchiveTarGz},
+			entries: []tarEntry{{name: "b.go", typeflag: tar.TypeLink, linkname: "a.go"}},
+			wantErr: true,
+		},
+		{
+			name:    "rejects hard links outside the root",
+			formats: []string{ArchiveTarGz},
+			entries: []tarEntry{{name: "passwd", typeflag: tar.TypeLink, linkname: "../../etc/passwd"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, format := range tt.formats {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				tmpDir := t.TempDir()
+				dir, cleanup, err := extractTestArchive(t, tmpDir, format, tt.entries)
+
+				if tt.wantErr {
+					if !errors.Is(err, models.ErrInvalidArchive) {
+						t.Fatalf("expected ErrInvalidArchive, got %v", err)
+					}
+					if _, err := os.Lstat(filepath.Join(tmpDir, "escaped-poc")); !os.IsNotExist(err) {
+						t.Fatalf("extraction created files outside the archive root: %v", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				defer cleanup()
+
+				var got []string
+				filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+					if err == nil && !info.IsDir() {
+						rel, _ := filepath.Rel(dir, path)
+						got = append(got, filepath.ToSlash(rel))
+					}
+					return nil
+				})
+				if len(got) != len(tt.files) {
+					t.Fatalf("extracted %v, want %d files", got, len(tt.files))
+				}
+				for name, want := range tt.files {
+					content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+					if err != nil {
+						t.Fatalf("failed to read %s: %v", name, err)
+					}
+					if string(content) != want {
+						t.Errorf("%s = %q, want %q", name, content, want)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestExtractorLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries int
+		written int64
+		files   []tarEntry
+		wantErr bool
+	}{
+		{
+			name:    "entries up to the limit",
+			entries: maxArchiveEntries - 2,
+			files:   []tarEntry{{name: "a", typeflag: tar.TypeReg}, {name: "b", typeflag: tar.TypeReg}},
+		},
+		{
+			name:    "entries over the limit",
+			entries: maxArchiveEntries - 1,
+			files:   []tarEntry{{name: "a", typeflag: tar.TypeReg}, {name: "b", typeflag: tar.TypeReg}},
+			wantErr: true,
+		},
+		{
+			name:    "size up to the limit",
+			written: maxExtractedBytes - 4,
+			files:   []tarEntry{{name: "a", typeflag: tar.TypeReg, body: "abcd"}},
+		},
+		{
+			name:    "size over the limit",
+			written: maxExtractedBytes - 4,
+			files:   []tarEntry{{name: "a", typeflag: tar.TypeReg, body: "abcde"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Start from a nearly exhausted budget instead of writing a
+			// gigabyte or a hundred thousand files
+			x := &extractor{root: t.TempDir(), entries: tt.entries, written: tt.written}
+			err := x.extractTarGz(bytes.NewReader(buildTarGz(t, tt.files)))
+			if tt.wantErr != errors.Is(err, models.ErrInvalidArchive) {
+				t.Fatalf("extractTarGz() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
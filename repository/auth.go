@@ -8,6 +8,7 @@ import (
 	"mcp-go-server/database"
 	"mcp-go-server/domain"
 	"net/http"
+	"sync"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/github"
@@ -16,6 +17,7 @@ import (
 var (
 	githubOauthConfig *oauth2.Config
 	userStore         = make(map[string]domain.User) // In-memory store (replace with DB in production)
+	userStoreMu       sync.RWMutex                   // Guards userStore, read by background indexing jobs
 )
 
 // Initialize OAuth config
@@ -160,12 +162,16 @@ func getGitHubUserEmail(accessToken string) (string, error) {
 
 // SaveUser saves user to storage
 func SaveUser(user domain.User) error {
+	userStoreMu.Lock()
+	defer userStoreMu.Unlock()
 	userStore[user.ID] = user
 	return nil
 }
 
 // GetUserByID retrieves user by ID
 func GetUserByID(userID string) (domain.User, error) {
+	userStoreMu.RLock()
+	defer userStoreMu.RUnlock()
 	user, exists := userStore[userID]
 	if !exists {
 		return domain.User{}, errors.New("user not found")
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"mcp-go-server/domain"
+	"mcp-go-server/helper"
+	"mcp-go-server/models"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The catalog is kept in memory and persisted to the directory given to
+// LoadCatalog
+var (
+	catalogMu   sync.RWMutex
+	fileStore   = make(map[catalogKey]map[string]domain.IndexedFile) // Indexed files per repository branch
+	commitStore = make(map[catalogKey]indexedCommit)                 // Last indexed commit per repository branch
+	ownerStore  = make(map[string]map[string]bool)                   // IDs of the users who indexed each repository
+	readerStore = make(map[string]map[string]bool)                   // IDs of the users who cloned each private repository
+)
+
+type catalogKey struct {
+	repository string
+	branch     string
+}
+
+// indexedCommit is the commit a branch was indexed at together with the
+// settings the files were selected and chunked with
+type indexedCommit struct {
+	sha      string
+	settings string
+}
+
+// ReplaceIndexedFiles replaces the catalog of files indexed for a repository branch
+func ReplaceIndexedFiles(repository, branch string, files []domain.IndexedFile) {
+	byPath := make(map[string]domain.IndexedFile, len(files))
+	for _, file := range files {
+		byPath[file.Path] = file
+	}
+
+	key := catalogKey{repository, branch}
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	catalogMu.Lock()
+	fileStore[key] = byPath
+	snapshot := snapshotBranch(key)
+	catalogMu.Unlock()
+
+	saveBranch(key, snapshot)
+}
+
+// UpdateIndexedFiles drops the given paths from the catalog of a repository
+// branch and records the re-indexed files in their place
+func UpdateIndexedFiles(repository, branch string, replacedPaths []string, files []domain.IndexedFile) {
+	key := catalogKey{repository, branch}
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	catalogMu.Lock()
+	byPath := fileStore[key]
+	if byPath == nil {
+		byPath = make(map[string]domain.IndexedFile, len(files))
+		fileStore[key] = byPath
+	}
+	for _, path := range replacedPaths {
+		delete(byPath, path)
+	}
+	for _, file := range files {
+		byPath[file.Path] = file
+	}
+	snapshot := snapshotBranch(key)
+	catalogMu.Unlock()
+
+	saveBranch(key, snapshot)
+}
+
+// AddRepositoryOwner records that a user indexed a repository, allowing them
+// to delete it
+func AddRepositoryOwner(repository, userID string) {
+	addRepositoryUser(ownerStore, repository, userID)
+}
+
+// IsRepositoryOwner reports whether a user indexed a repository
+func IsRepositoryOwner(repository, userID string) bool {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	return ownerStore[repository][userID]
+}
+
+// AddRepositoryReader records that a user cloned a private repository with
+// their own token, allowing them to read its indexed content
+func AddRepositoryReader(repository, userID string) {
+	addRepositoryUser(readerStore, repository, userID)
+}
+
+// addRepositoryUser adds a user to the owners or readers of a repository
+func addRepositoryUser(store map[string]map[string]bool, repository, userID string) {
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	catalogMu.Lock()
+	if store[repository][userID] {
+		catalogMu.Unlock()
+		return
+	}
+	if store[repository] == nil {
+		store[repository] = make(map[string]bool)
+	}
+	store[repository][userID] = true
+	access := snapshotAccess()
+	catalogMu.Unlock()
+
+	saveAccess(access)
+}
+
+// IsRepositoryReader reports whether a user cloned a private repository
+func IsRepositoryReader(repository, userID string) bool {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	return readerStore[repository][userID]
+}
+
+// IsPrivateBranch reports whether the files of a repository branch were
+// indexed from a private clone
+func IsPrivateBranch(repository, branch string) bool {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	for _, file := range fileStore[catalogKey{repository, branch}] {
+		if file.Private {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveIndexedRepository drops a repository branch, or every branch when
+// branch is empty, from the catalog. It returns the removed branches. Owners
+// and readers are forgotten with the last branch.
+func RemoveIndexedRepository(repository, branch string) []string {
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	catalogMu.Lock()
+	var branches []string
+	for key := range fileStore {
+		if key.repository != repository || (branch != "" && key.branch != branch) {
+			continue
+		}
+		branches = append(branches, key.branch)
+		delete(fileStore, key)
+		delete(commitStore, key)
+	}
+
+	remaining := false
+	for key := range fileStore {
+		if key.repository == repository {
+			remaining = true
+			break
+		}
+	}
+	if !remaining {
+		delete(ownerStore, repository)
+		delete(readerStore, repository)
+	}
+	access := snapshotAccess()
+	catalogMu.Unlock()
+
+	for _, removed := range branches {
+		saveBranch(catalogKey{repository, removed}, nil)
+	}
+	if !remaining {
+		saveAccess(access)
+	}
+
+	sort.Strings(branches)
+	return branches
+}
+
+// SetIndexedCommit records the commit SHA a repository branch was indexed at
+// and the indexing settings used
+func SetIndexedCommit(repository, branch, sha, settings string) {
+	key := catalogKey{repository, branch}
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	catalogMu.Lock()
+	commitStore[key] = indexedCommit{sha: sha, settings: settings}
+	snapshot := snapshotBranch(key)
+	catalogMu.Unlock()
+
+	saveBranch(key, snapshot)
+}
+
+// GetIndexedCommit returns the commit SHA a repository branch was last
+// indexed at and the indexing settings used, if any
+func GetIndexedCommit(repository, branch string) (string, string, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	commit, exists := commitStore[catalogKey{repository, branch}]
+	return commit.sha, commit.settings, exists
+}
+
+// GetIndexedFile retrieves a single indexed file
+func GetIndexedFile(repository, branch, path string) (domain.IndexedFile, error) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	file, exists := fileStore[catalogKey{repository, branch}][path]
+	if !exists {
+		return domain.IndexedFile{}, models.ErrFileNotFound
+	}
+	return file, nil
+}
+
+// ListIndexedFiles returns all indexed files sorted by repository, branch and path
+func ListIndexedFiles() []domain.IndexedFile {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	var files []domain.IndexedFile
+	for _, byPath := range fileStore {
+		for _, file := range byPath {
+			files = append(files, file)
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Repository != files[j].Repository {
+			return files[i].Repository < files[j].Repository
+		}
+		if files[i].Branch != files[j].Branch {
+			return files[i].Branch < files[j].Branch
+		}
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// ListIndexedRepositories returns every indexed repository branch sorted by
+// repository and branch
+func ListIndexedRepositories() []domain.Repository {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	var repos []domain.Repository
+	for key, byPath := range fileStore {
+		repo := domain.Repository{
+			Name:      key.repository,
+			Branch:    key.branch,
+			FileCount: len(byPath),
+			CommitSHA: commitStore[key].sha,
+		}
+		if slash := strings.LastIndex(key.repository, "/"); slash >= 0 {
+			repo.Owner = key.repository[:slash]
+			repo.Name = key.repository[slash+1:]
+		}
+		if ref, ok := helper.ParseRepoID(key.repository); ok {
+			repo.URL = ref.WebURL()
+		}
+		for _, file := range byPath {
+			repo.ChunkCount += file.ChunkCount
+			if file.IndexedAt > repo.IndexedAt {
+				repo.IndexedAt = file.IndexedAt
+			}
+			repo.Private = repo.Private || file.Private
+		}
+		repos = append(repos, repo)
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].Owner+"/"+repos[i].Name != repos[j].Owner+"/"+repos[j].Name {
+			return repos[i].Owner+"/"+repos[i].Name < repos[j].Owner+"/"+repos[j].Name
+		}
+		return repos[i].Branch < repos[j].Branch
+	})
+	return repos
+}
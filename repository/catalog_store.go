@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mcp-go-server/domain"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// catalogDir holds the persisted catalog; empty until LoadCatalog is called,
+// which keeps the catalog in memory only
+var catalogDir string
+
+// persistMu serialises catalog writes so files on disk follow the order of
+// the updates
+var persistMu sync.Mutex
+
+// accessFileName is the file listing the owners and readers of every
+// repository
+const accessFileName = "access.json"
+
+// storedBranch is the persisted catalog of a repository branch
+type storedBranch struct {
+	Repository string               `json:"repository"`
+	Branch     string               `json:"branch"`
+	CommitSHA  string               `json:"commit_sha,omitempty"`
+	Settings   string               `json:"settings,omitempty"`
+	Files      []domain.IndexedFile `json:"files"`
+}
+
+// storedAccess is the persisted list of owners and readers by repository
+type storedAccess struct {
+	Owners  map[string][]string `json:"owners"`
+	Readers map[string][]string `json:"readers"`
+}
+
+// LoadCatalog restores the catalog persisted in dir and keeps persisting
+// every later change there, so owners, readers, indexed files and commits
+// survive restarts. Unreadable branch files are skipped with a warning.
+func LoadCatalog(dir string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "branches"), 0o700); err != nil {
+		return fmt.Errorf("failed to create catalog directory: %w", err)
+	}
+
+	access := storedAccess{}
+	data, err := os.ReadFile(filepath.Join(dir, accessFileName))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &access); err != nil {
+			return fmt.Errorf("failed to read %s: %w", accessFileName, err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read %s: %w", accessFileName, err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "branches"))
+	if err != nil {
+		return fmt.Errorf("failed to read catalog directory: %w", err)
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		var branch storedBranch
+		data, err := os.ReadFile(filepath.Join(dir, "branches", entry.Name()))
+		if err == nil {
+			err = json.Unmarshal(data, &branch)
+		}
+		if err != nil {
+			log.Printf("⚠️  Skipping catalog file %s: %v", entry.Name(), err)
+			continue
+		}
+
+		key := catalogKey{branch.Repository, branch.Branch}
+		byPath := make(map[string]domain.IndexedFile, len(branch.Files))
+		for _, file := range branch.Files {
+			byPath[file.Path] = file
+		}
+		fileStore[key] = byPath
+		if branch.CommitSHA != "" {
+			commitStore[key] = indexedCommit{sha: branch.CommitSHA, settings: branch.Settings}
+		}
+	}
+
+	for repository, users := range access.Owners {
+		ownerStore[repository] = userSet(users)
+	}
+	for repository, users := range access.Readers {
+		readerStore[repository] = userSet(users)
+	}
+
+	catalogDir = dir
+	log.Printf("📚 Loaded catalog of %d repository branches from %s", len(fileStore), dir)
+	return nil
+}
+
+// userSet turns a list of user IDs into a set
+func userSet(users []string) map[string]bool {
+	set := make(map[string]bool, len(users))
+	for _, user := range users {
+		set[user] = true
+	}
+	return set
+}
+
+// branchFileName returns the name of the file persisting a repository
+// branch. Names are hashed since repositories and branches contain slashes.
+func branchFileName(key catalogKey) string {
+	sum := sha256.Sum256([]byte(key.repository + "\x00" + key.branch))
+	return hex.EncodeToString(sum[:16]) + ".json"
+}
+
+// snapshotBranch copies the catalog of a repository branch for persisting.
+// The caller must hold catalogMu; nil means the branch is no longer indexed.
+func snapshotBranch(key catalogKey) *storedBranch {
+	byPath, hasFiles := fileStore[key]
+	commit, hasCommit := commitStore[key]
+	if !hasFiles && !hasCommit {
+		return nil
+	}
+
+	branch := &storedBranch{
+		Repository: key.repository,
+		Branch:     key.branch,
+		CommitSHA:  commit.sha,
+		Settings:   commit.settings,
+		Files:      make([]domain.IndexedFile, 0, len(byPath)),
+	}
+	for _, file := range byPath {
+		branch.Files = append(branch.Files, file)
+	}
+	sort.Slice(branch.Files, func(i, j int) bool { return branch.Files[i].Path < branch.Files[j].Path })
+	return branch
+}
+
+// snapshotAccess copies the owners and readers for persisting. The caller
+// must hold catalogMu.
+func snapshotAccess() storedAccess {
+	access := storedAccess{Owners: make(map[string][]string), Readers: make(map[string][]string)}
+	for repository, users := range ownerStore {
+		access.Owners[repository] = sortedUsers(users)
+	}
+	for repository, users := range readerStore {
+		access.Readers[repository] = sortedUsers(users)
+	}
+	return access
+}
+
+// sortedUsers lists the user IDs of a set in order
+func sortedUsers(users map[string]bool) []string {
+	list := make([]string, 0, len(users))
+	for user := range users {
+		list = append(list, user)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// saveBranch writes or, for a nil snapshot, removes the file of a
+// repository branch. The caller must hold persistMu.
+func saveBranch(key catalogKey, branch *storedBranch) {
+	if catalogDir == "" {
+		return
+	}
+
+	path := filepath.Join(catalogDir, "branches", branchFileName(key))
+	if branch == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("⚠️  Failed to remove catalog of %s@%s: %v", key.repository, key.branch, err)
+		}
+		return
+	}
+	if err := writeCatalogFile(path, branch); err != nil {
+		log.Printf("⚠️  Failed to persist catalog of %s@%s: %v", key.repository, key.branch, err)
+	}
+}
+
+// saveAccess writes the owners and readers. The caller must hold persistMu.
+func saveAccess(access storedAccess) {
+	if catalogDir == "" {
+		return
+	}
+	if err := writeCatalogFile(filepath.Join(catalogDir, accessFileName), access); err != nil {
+		log.Printf("⚠️  Failed to persist repository owners and readers: %v", err)
+	}
+}
+
+// writeCatalogFile replaces path with the JSON encoding of value through a
+// temporary file, so readers never see a partial file
+func writeCatalogFile(path string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".catalog-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
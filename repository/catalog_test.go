@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"mcp-go-server/domain"
+	"testing"
+)
+
+// resetCatalog forgets the in-memory catalog and stops persisting it
+func resetCatalog(t *testing.T) {
+	t.Helper()
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	fileStore = make(map[catalogKey]map[string]domain.IndexedFile)
+	commitStore = make(map[catalogKey]indexedCommit)
+	ownerStore = make(map[string]map[string]bool)
+	readerStore = make(map[string]map[string]bool)
+	catalogDir = ""
+}
+
+func TestLoadCatalog(t *testing.T) {
+	dir := t.TempDir()
+	resetCatalog(t)
+	t.Cleanup(func() { resetCatalog(t) })
+
+	if err := LoadCatalog(dir); err != nil {
+		t.Fatalf("LoadCatalog() error = %v", err)
+	}
+	ReplaceIndexedFiles("owner/repo", "main", []domain.IndexedFile{
+		{Repository: "owner/repo", Branch: "main", Path: "a.go", ChunkCount: 2, Private: true},
+		{Repository: "owner/repo", Branch: "main", Path: "b.go", ChunkCount: 1, Private: true},
+	})
+	UpdateIndexedFiles("owner/repo", "main", []string{"b.go"}, nil)
+	SetIndexedCommit("owner/repo", "main", "abc123", "settings")
+	AddRepositoryOwner("owner/repo", "alice")
+	AddRepositoryReader("owner/repo", "alice")
+	ReplaceIndexedFiles("owner/other", "dev", []domain.IndexedFile{{Repository: "owner/other", Branch: "dev", Path: "c.go"}})
+	AddRepositoryOwner("owner/other", "bob")
+	RemoveIndexedRepository("owner/other", "")
+
+	// A restarted server only has what was persisted
+	resetCatalog(t)
+	if err := LoadCatalog(dir); err != nil {
+		t.Fatalf("LoadCatalog() after restart error = %v", err)
+	}
+
+	if _, err := GetIndexedFile("owner/repo", "main", "a.go"); err != nil {
+		t.Errorf("GetIndexedFile(a.go) error = %v", err)
+	}
+	if _, err := GetIndexedFile("owner/repo", "main", "b.go"); err == nil {
+		t.Error("GetIndexedFile(b.go) found a file that was removed before the restart")
+	}
+	if sha, settings, ok := GetIndexedCommit("owner/repo", "main"); sha != "abc123" || settings != "settings" || !ok {
+		t.Errorf("GetIndexedCommit() = %q, %q, %v, want abc123, settings, true", sha, settings, ok)
+	}
+	if !IsRepositoryOwner("owner/repo", "alice") || !IsRepositoryReader("owner/repo", "alice") {
+		t.Error("alice lost ownership or read access of owner/repo")
+	}
+	if IsRepositoryReader("owner/repo", "bob") {
+		t.Error("bob became a reader of owner/repo")
+	}
+	if !IsPrivateBranch("owner/repo", "main") {
+		t.Error("owner/repo@main is no longer private")
+	}
+	if IsRepositoryOwner("owner/other", "bob") || len(ListIndexedRepositories()) != 1 {
+		t.Errorf("deleted repository owner/other came back: %+v", ListIndexedRepositories())
+	}
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mcp-go-server/domain"
+	"mcp-go-server/models"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// HeadCommit returns the SHA of the commit checked out in repoPath
+func HeadCommit(ctx context.Context, repoPath string) (string, error) {
+	output, err := runGit(ctx, repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CommitExists reports whether a commit is available in the clone at repoPath
+func CommitExists(ctx context.Context, repoPath, sha string) bool {
+	_, err := runGit(ctx, repoPath, "cat-file", "-e", sha+"^{commit}")
+	return err == nil
+}
+
+// DiffCommits lists the files changed between two commits using
+// git diff --name-status. Renamed files count as removed at their old path
+// and changed at their new path.
+func DiffCommits(ctx context.Context, repoPath, fromSHA, toSHA string) (domain.FileChanges, error) {
+	output, err := runGit(ctx, repoPath, "diff", "--name-status", "-z", "-M", fromSHA, toSHA)
+	if err != nil {
+		return domain.FileChanges{}, fmt.Errorf("failed to diff %s..%s: %w", fromSHA, toSHA, err)
+	}
+
+	var changes domain.FileChanges
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+
+		// Renames and copies carry the old and the new path
+		if status[0] == 'R' || status[0] == 'C' {
+			if i+2 >= len(fields) {
+				return domain.FileChanges{}, fmt.Errorf("malformed diff entry: %s", status)
+			}
+			oldPath, newPath := fields[i+1], fields[i+2]
+			i += 2
+			if status[0] == 'R' {
+				changes.Removed = append(changes.Removed, oldPath)
+			}
+			changes.Changed = append(changes.Changed, newPath)
+			continue
+		}
+
+		if i+1 >= len(fields) {
+			return domain.FileChanges{}, fmt.Errorf("malformed diff entry: %s", status)
+		}
+		path := fields[i+1]
+		i++
+
+		switch status[0] {
+		case 'D':
+			changes.Removed = append(changes.Removed, path)
+		default: // A, M, T
+			changes.Changed = append(changes.Changed, path)
+		}
+	}
+
+	return changes, nil
+}
+
+// ResolveRef checks that ref exists as a branch or tag of the remote
+// repository and returns it. An empty ref resolves to the remote's default
+// branch. A missing ref yields models.ErrRefNotFound.
+func ResolveRef(ctx context.Context, repoURL, ref string) (string, error) {
+	if ref == "" {
+		output, err := runGit(ctx, "", "ls-remote", "--symref", "--", repoURL, "HEAD")
+		if err != nil {
+			return "", fmt.Errorf("failed to query remote repository: %w", err)
+		}
+
+		// The symref line reads "ref: refs/heads/<branch>\tHEAD"
+		for _, line := range strings.Split(string(output), "\n") {
+			if strings.HasPrefix(line, "ref: refs/heads/") && strings.HasSuffix(line, "\tHEAD") {
+				return strings.TrimSuffix(strings.TrimPrefix(line, "ref: refs/heads/"), "\tHEAD"), nil
+			}
+		}
+		return "", errors.New("remote repository has no default branch")
+	}
+
+	output, err := runGit(ctx, "", "ls-remote", "--", repoURL, "refs/heads/"+ref, "refs/tags/"+ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to query remote repository: %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && (fields[1] == "refs/heads/"+ref || fields[1] == "refs/tags/"+ref) {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", models.ErrRefNotFound, ref)
+}
+
+// FetchCommit fetches a single commit into a shallow clone, for example the
+// previously indexed commit to diff against. Complete clones such as mirror
+// worktrees are left alone, as a shallow fetch would truncate their history.
+func FetchCommit(ctx context.Context, repoPath, sha string) error {
+	output, err := runGit(ctx, repoPath, "rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return fmt.Errorf("failed to inspect clone: %w", err)
+	}
+	if strings.TrimSpace(string(output)) != "true" {
+		return fmt.Errorf("commit %s is not in the repository", sha)
+	}
+	if _, err := runGit(ctx, repoPath, "fetch", "--depth", "1", "origin", sha); err != nil {
+		return fmt.Errorf("failed to fetch commit %s: %w", sha, err)
+	}
+	return nil
+}
+
+// gitTokenEnv passes the GitHub token to the credential helper. The token
+// only lives in the environment of the git process, never in its arguments,
+// remote URLs or config files.
+const gitTokenEnv = "MCP_GIT_TOKEN"
+
+// gitCredentialHelper answers git's credential requests with the token from
+// gitTokenEnv. It is configured for github.com only, so the token is never
+// sent to other hosts.
+const gitCredentialHelper = `!f() { test "$1" = get && echo username=x-access-token && echo "password=$` + gitTokenEnv + `"; }; f`
+
+type gitTokenKey struct{}
+
+// WithGitToken returns a context whose git commands authenticate to GitHub
+// with token, so private repositories can be cloned. An empty token leaves
+// git anonymous.
+func WithGitToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, gitTokenKey{}, token)
+}
+
+// HasGitToken reports whether ctx carries a token attached with WithGitToken
+func HasGitToken(ctx context.Context) bool {
+	token, _ := ctx.Value(gitTokenKey{}).(string)
+	return token != ""
+}
+
+// IsPublicRepository reports whether repoURL can be read anonymously.
+// Configured credential helpers are bypassed, and any failure, including a
+// network error, counts as private.
+func IsPublicRepository(ctx context.Context, repoURL string) bool {
+	_, err := runGit(WithGitToken(ctx, ""), "", "-c", "credential.helper=", "ls-remote", "--", repoURL, "HEAD")
+	return err == nil
+}
+
+// runGit runs a git command in dir and returns its standard output. Git
+// never prompts for credentials, so unauthorized clones fail instead of
+// hanging. The GitHub token attached with WithGitToken is handed to git
+// through a command line credential helper.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	token, _ := ctx.Value(gitTokenKey{}).(string)
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes") // Fail instead of prompting for ssh:// remotes
+	}
+	if token != "" {
+		// Reset configured helpers first so none of them stores the token
+		args = append([]string{
+			"-c", "credential.helper=",
+			"-c", "credential.https://github.com.helper=" + gitCredentialHelper,
+		}, args...)
+		env = append(env, gitTokenEnv+"="+token)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			if token != "" {
+				message = strings.ReplaceAll(message, token, "[REDACTED]")
+			}
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+	return output, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// gitTestRepo creates a repository in a temporary directory and returns a
+// function running git in it
+func gitTestRepo(t *testing.T) (string, func(args ...string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	git("init", "-q")
+	return dir, git
+}
+
+// writeTestFiles writes files relative to dir
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiffCommits(t *testing.T) {
+	// Rename detection needs enough shared content
+	body := strings.Repeat("line of code that stays the same\n", 20)
+
+	tests := []struct {
+		name        string
+		before      map[string]string
+		change      func(t *testing.T, dir string, git func(args ...string) string)
+		wantChanged []string
+		wantRemoved []string
+	}{
+		{
+			name:   "added and modified",
+			before: map[string]string{"a.go": "package a\n"},
+			change: func(t *testing.T, dir string, git func(args ...string) string) {
+				writeTestFiles(t, dir, map[string]string{"a.go": "package a\n\nvar x = 1\n", "b.go": "package a\n"})
+			},
+			wantChanged: []string{"a.go", "b.go"},
+		},
+		{
+			name:   "deleted",
+			before: map[string]string{"a.go": "package a\n", "b.go": "package b\n"},
+			change: func(t *testing.T, dir string, git func(args ...string) string) {
+				git("rm", "-q", "b.go")
+			},
+			wantRemoved: []string{"b.go"},
+		},
+		{
+			name:   "renamed",
+			before: map[string]string{"old/name.go": body},
+			change: func(t *testing.T, dir string, git func(args ...string) string) {
+				git("mv", "old/name.go", "old/renamed.go")
+			},
+			wantChanged: []string{"old/renamed.go"},
+			wantRemoved: []string{"old/name.go"},
+		},
+		{
+			name:   "renamed and edited",
+			before: map[string]string{"util.go": body},
+			change: func(t *testing.T, dir string, git func(args ...string) string) {
+				git("mv", "util.go", "helpers.go")
+				writeTestFiles(t, dir, map[string]string{"helpers.go": body + "one more line\n"})
+			},
+			wantChanged: []string{"helpers.go"},
+			wantRemoved: []string{"util.go"},
+		},
+		{
+			name:   "unusual path characters",
+			before: map[string]string{"with space.go": "package a\n", "tab\there.go": "package a\n"},
+			change: func(t *testing.T, dir string, git func(args ...string) string) {
+				git("rm", "-q", "with space.go")
+				writeTestFiles(t, dir, map[string]string{"tab\there.go": "package b\n", "ünïcode.go": "package a\n"})
+			},
+			wantChanged: []string{"tab\there.go", "ünïcode.go"},
+			wantRemoved: []string{"with space.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, git := gitTestRepo(t)
+			writeTestFiles(t, dir, tt.before)
+			git("add", "-A")
+			git("commit", "-q", "-m", "before")
+			from := git("rev-parse", "HEAD")
+
+			tt.change(t, dir, git)
+			git("add", "-A")
+			git("commit", "-q", "-m", "after")
+			to := git("rev-parse", "HEAD")
+
+			changes, err := DiffCommits(context.Background(), dir, from, to)
+			if err != nil {
+				t.Fatalf("DiffCommits() error = %v", err)
+			}
+			sort.Strings(changes.Changed)
+			sort.Strings(changes.Removed)
+			if !reflect.DeepEqual(changes.Changed, tt.wantChanged) {
+				t.Errorf("DiffCommits() changed = %q, want %q", changes.Changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(changes.Removed, tt.wantRemoved) {
+				t.Errorf("DiffCommits() removed = %q, want %q", changes.Removed, tt.wantRemoved)
+			}
+		})
+	}
+}
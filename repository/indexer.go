@@ -5,143 +5,457 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
-	"mcp-go-server/database"
+	"mcp-go-server/chunker"
 	"mcp-go-server/domain"
 	"mcp-go-server/helper"
+	"mcp-go-server/models"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/pinecone-io/go-pinecone/pinecone"
-	"github.com/sashabaranov/go-openai"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
-// CloneRepository clones a Git repository to temporary directory
-func CloneRepository(repoURL, branch string) (string, error) {
+// ProgressFunc receives indexing progress updates
+type ProgressFunc func(progress models.IndexProgress)
+
+// CloneRepository checks out a branch or tag of repoURL and returns its path
+// together with a function that removes the checkout. With the mirror cache
+// enabled the checkout is a worktree of a cached bare mirror; otherwise it is
+// a shallow, single-branch clone into a temporary directory. A missing ref
+// yields models.ErrRefNotFound. The clone is aborted when ctx is cancelled.
+func CloneRepository(ctx context.Context, repoURL, branch string) (string, func(), error) {
+	if cacheDir, maxBytes, ok := mirrorCacheConfig(); ok {
+		return checkoutFromMirror(ctx, cacheDir, maxBytes, repoURL, branch)
+	}
+
 	log.Printf("📥 Creating temporary directory for repository...")
 	// Create temporary directory
 	tempDir, err := ioutil.TempDir("", "repo-")
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp directory: %w", err)
+		return "", nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
 	log.Printf("📁 Temporary directory created: %s", tempDir)
 
-	// Clone repository
-	log.Printf("🔗 Cloning repository from: %s", repoURL)
-	cmd := exec.Command("git", "clone", repoURL, tempDir)
-	if err := cmd.Run(); err != nil {
+	// Clone only the tip of the requested ref
+	log.Printf("🔗 Cloning %s of repository from: %s", branch, repoURL)
+	if _, err := runGit(ctx, "", "clone", "--depth", "1", "--branch", branch, "--single-branch", "--", repoURL, tempDir); err != nil {
 		os.RemoveAll(tempDir)
-		return "", fmt.Errorf("failed to clone repository: %w", err)
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+		if strings.Contains(err.Error(), "not found in upstream") {
+			return "", nil, fmt.Errorf("%w: %s", models.ErrRefNotFound, branch)
+		}
+		return "", nil, fmt.Errorf("failed to clone repository: %w", err)
 	}
 	log.Printf("✅ Repository cloned successfully")
 
-	// Checkout specific branch if specified
-	if branch != "" && branch != "main" && branch != "master" {
-		log.Printf("🌿 Checking out branch: %s", branch)
-		cmd = exec.Command("git", "checkout", branch)
-		cmd.Dir = tempDir
-		if err := cmd.Run(); err != nil {
-			os.RemoveAll(tempDir)
-			return "", fmt.Errorf("failed to checkout branch %s: %w", branch, err)
-		}
-		log.Printf("✅ Branch checkout completed")
+	return tempDir, func() { os.RemoveAll(tempDir) }, nil
+}
+
+// ProcessRepositoryFiles processes all files in repository, reporting
+// progress after every file and stored batch. Batches that fail to embed or
+// store are returned rather than aborting the run. Processing stops with
+// ctx.Err() when ctx is cancelled.
+func ProcessRepositoryFiles(ctx context.Context, repoPath, repoURL, branch string, opts ProcessOptions, onProgress ProgressFunc) (int, int, []models.BatchFailure, error) {
+	indexedFiles, fileCount, chunkCount, failures, err := processFiles(ctx, repoPath, repoURL, branch, nil, opts, onProgress)
+
+	// Record indexed files so they can be served in full later
+	if err == nil {
+		ReplaceIndexedFiles(helper.ExtractRepoName(repoURL), branch, indexedFiles)
 	}
 
-	return tempDir, nil
+	return fileCount, chunkCount, failures, err
 }
 
-// ProcessRepositoryFiles processes all files in repository
-func ProcessRepositoryFiles(repoPath, repoURL, branch string) (int, int, error) {
+// ProcessChangedFiles re-indexes only the files listed in changes. Vectors of
+// removed files are deleted, as are the previous vectors of changed files
+// since their new version may split into fewer chunks.
+func ProcessChangedFiles(ctx context.Context, repoPath, repoURL, branch string, changes domain.FileChanges, opts ProcessOptions, onProgress ProgressFunc) (int, int, []models.BatchFailure, error) {
+	repoName := helper.ExtractRepoName(repoURL)
+	log.Printf("🔀 Re-indexing %d changed and removing %d deleted files", len(changes.Changed), len(changes.Removed))
+
+	stalePaths := append(append([]string{}, changes.Removed...), changes.Changed...)
+	if err := DeleteFileVectors(ctx, repoName, branch, stalePaths); err != nil {
+		return 0, 0, nil, err
+	}
+
+	changed := make(map[string]bool, len(changes.Changed))
+	for _, path := range changes.Changed {
+		changed[path] = true
+	}
+
+	indexedFiles, fileCount, chunkCount, failures, err := processFiles(ctx, repoPath, repoURL, branch, func(relPath string) bool {
+		return changed[relPath]
+	}, opts, onProgress)
+
+	if err == nil {
+		UpdateIndexedFiles(repoName, branch, stalePaths, indexedFiles)
+	}
+
+	return fileCount, chunkCount, failures, err
+}
+
+// ProcessOptions tunes how repository files are processed
+type ProcessOptions struct {
+	// Concurrency is the number of files read, chunked and embedded in
+	// parallel. Values below 1 process files one at a time.
+	Concurrency int
+
+	// Chunking selects the chunking strategy, window size and overlap
+	Chunking chunker.Options
+
+	// CommitSHA is the indexed commit recorded in the chunk metadata, if any
+	CommitSHA string
+
+	// Private marks the files and chunks of a repository that could only be
+	// cloned with a user's token, hiding them from other users
+	Private bool
+
+	// Include and Exclude are globs matched against slash-separated paths
+	// relative to the repository root. When Include is set, only matching
+	// files are indexed; Exclude wins over Include.
+	Include []string
+	Exclude []string
+}
+
+// processFiles processes every file of repoPath accepted by include (all
+// files when include is nil) on a bounded pool of workers, returning the
+// indexed files and the batches that failed to embed or store. The first
+// fatal error or a cancelled ctx stops every worker.
+func processFiles(ctx context.Context, repoPath, repoURL, branch string, include func(relPath string) bool, opts ProcessOptions, onProgress ProgressFunc) ([]domain.IndexedFile, int, int, []models.BatchFailure, error) {
 	log.Printf("🔍 Scanning repository for files to process...")
-	fileCount := 0
-	chunkCount := 0
-	processedFiles := 0
-	skippedFiles := 0
+	repoName := helper.ExtractRepoName(repoURL)
+	indexedAt := time.Now().UTC().Format(time.RFC3339)
 
-	// First pass: count total files
-	totalFiles := 0
-	filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() || strings.HasPrefix(info.Name(), ".") {
-			return nil
+	// First pass: collect the files to process in walk order
+	paths, err := collectFiles(ctx, repoPath, include, opts)
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+	totalFiles := len(paths)
+	log.Printf("📊 Found %d files to process", totalFiles)
+
+	tracker := &progressTracker{
+		progress: models.IndexProgress{
+			Repository: repoName,
+			Branch:     branch,
+			Status:     "processing",
+			TotalFiles: totalFiles,
+			StartTime:  indexedAt,
+		},
+		onProgress: onProgress,
+	}
+	tracker.update(fmt.Sprintf("Found %d files to process", totalFiles), nil)
+
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > totalFiles {
+		workers = totalFiles
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Every worker batches the chunks of the files it processes
+	pipelines := make([]*indexPipeline, 0, workers)
+	defer func() {
+		for _, pipeline := range pipelines {
+			pipeline.close()
 		}
-		if !helper.IsBinaryFile(path) {
-			totalFiles++
+	}()
+	for i := 0; i < workers; i++ {
+		pipeline, err := newIndexPipeline(workerCtx, func(count int) {
+			tracker.update("", func(progress *models.IndexProgress) {
+				progress.CurrentChunk += count
+				progress.Message = fmt.Sprintf("Stored %d/%d chunks", progress.CurrentChunk, progress.TotalChunks)
+			})
+		})
+		if err != nil {
+			return nil, 0, 0, nil, err
 		}
-		return nil
-	})
+		pipelines = append(pipelines, pipeline)
+	}
+	if workers > 1 {
+		log.Printf("👷 Processing files with %d workers", workers)
+	}
 
-	log.Printf("📊 Found %d files to process", totalFiles)
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+		skipped  int32
+	)
+	fail := func(err error) {
+		errMu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		errMu.Unlock()
+		cancel()
+	}
+
+	// Results are stored by walk position so the outcome does not depend on
+	// which worker finishes first
+	results := make([]*domain.IndexedFile, totalFiles)
+	jobs := make(chan int)
+
+	for _, pipeline := range pipelines {
+		wg.Add(1)
+		go func(pipeline *indexPipeline) {
+			defer wg.Done()
+			for position := range jobs {
+				file, err := processRepositoryFile(workerCtx, pipeline, repoPath, paths[position], repoURL, branch, indexedAt, opts, tracker)
+				if err != nil {
+					fail(err)
+					return
+				}
+				if file == nil {
+					atomic.AddInt32(&skipped, 1)
+					continue
+				}
+				results[position] = file
+			}
+
+			// Store the chunks of the last, partially filled batches
+			if workerCtx.Err() == nil {
+				if err := pipeline.flush(); err != nil {
+					fail(err)
+				}
+			}
+		}(pipeline)
+	}
+
+feed:
+	for position := range paths {
+		select {
+		case jobs <- position:
+		case <-workerCtx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+
+	// Files count the chunks that were actually stored
+	var indexedFiles []domain.IndexedFile
+	var failures []models.BatchFailure
+	chunkCount := 0
+	for _, file := range results {
+		if file == nil {
+			continue
+		}
+		for _, pipeline := range pipelines {
+			file.ChunkCount += pipeline.storedChunks(filepath.FromSlash(file.Path))
+		}
+		chunkCount += file.ChunkCount
+		indexedFiles = append(indexedFiles, *file)
+	}
+	for _, pipeline := range pipelines {
+		failures = append(failures, pipeline.failures...)
+	}
+	fileCount := len(indexedFiles)
+
+	log.Printf("📈 Processing completed:")
+	log.Printf("   - Files processed: %d", fileCount)
+	log.Printf("   - Files skipped: %d", skipped)
+	log.Printf("   - Total chunks created: %d", chunkCount)
+	log.Printf("   - Failed batches: %d", len(failures))
+
+	return indexedFiles, fileCount, chunkCount, failures, firstErr
+}
+
+// collectFiles walks repoPath and returns the files that may be indexed:
+// not hidden, outside .git, not matched by .gitignore or .mcpignore rules,
+// accepted by the include and exclude globs of opts and by include, and not
+// binary by extension. Ignored directories are not descended into.
+func collectFiles(ctx context.Context, repoPath string, include func(relPath string) bool, opts ProcessOptions) ([]string, error) {
+	var paths []string
+	var ignores helper.IgnoreMatcher
+	ignoredFiles := 0
 
 	err := filepath.Walk(repoPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip directories and hidden files
-		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
-			if info.IsDir() && strings.Contains(path, ".git") {
+		// Stop walking as soon as the caller gives up
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		relPath, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if info.IsDir() {
+			if info.Name() == ".git" {
 				return filepath.SkipDir
 			}
+			if relPath != "." && (ignores.Ignored(relPath, true) || matchesAnyGlob(opts.Exclude, relPath)) {
+				return filepath.SkipDir
+			}
+
+			// Rules of this directory apply to everything below it
+			base := relPath
+			if base == "." {
+				base = ""
+			}
+			loadIgnoreFiles(&ignores, path, base)
 			return nil
 		}
 
-		// Skip binary files
-		if helper.IsBinaryFile(path) {
-			skippedFiles++
+		// Skip hidden files
+		if strings.HasPrefix(info.Name(), ".") {
 			return nil
 		}
 
-		// Read file content
-		content, err := ioutil.ReadFile(path)
-		if err != nil {
-			skippedFiles++
-			return nil // Skip files we can't read
+		// Skip ignored files and files outside the requested globs
+		if ignores.Ignored(relPath, false) || matchesAnyGlob(opts.Exclude, relPath) ||
+			(len(opts.Include) > 0 && !matchesAnyGlob(opts.Include, relPath)) {
+			ignoredFiles++
+			return nil
 		}
 
-		// Skip large files and binary content
-		if len(content) > 100000 || helper.ContainsBinaryData(content) {
-			skippedFiles++
+		// Skip files outside the requested set
+		if include != nil && !include(relPath) {
 			return nil
 		}
 
-		// Get relative path
-		relPath, err := filepath.Rel(repoPath, path)
-		if err != nil {
-			relPath = path
+		// Skip binary files
+		if helper.IsBinaryFile(path) {
+			return nil
 		}
 
-		processedFiles++
-		log.Printf("📄 Processing file %d/%d: %s", processedFiles, totalFiles, relPath)
+		paths = append(paths, path)
+		return nil
+	})
+
+	if ignoredFiles > 0 {
+		log.Printf("🙈 Ignored %d files matching ignore rules or globs", ignoredFiles)
+	}
+	return paths, err
+}
 
-		// Process file
-		chunks, err := processFile(string(content), relPath, repoURL, branch)
+// loadIgnoreFiles adds the rules of the ignore files found in dir
+func loadIgnoreFiles(ignores *helper.IgnoreMatcher, dir, base string) {
+	for _, name := range helper.IgnoreFileNames {
+		content, err := ioutil.ReadFile(filepath.Join(dir, name))
 		if err != nil {
-			log.Printf("⚠️  Failed to process file %s: %v", relPath, err)
-			return nil // Skip files that fail processing
+			continue // Ignore files are optional
 		}
+		ignores.AddRules(base, string(content))
+	}
+}
 
-		fileCount++
-		chunkCount += chunks
-		log.Printf("✅ Processed %s (%d chunks)", relPath, chunks)
-		return nil
+// matchesAnyGlob reports whether relPath matches one of patterns
+func matchesAnyGlob(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if helper.MatchPathGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// processRepositoryFile reads a file and queues its chunks in pipeline. It
+// returns nil without an error for files that are skipped.
+func processRepositoryFile(ctx context.Context, pipeline *indexPipeline, repoPath, path, repoURL, branch, indexedAt string, opts ProcessOptions, tracker *progressTracker) (*domain.IndexedFile, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Read file content
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil // Skip files we can't read
+	}
+
+	// Skip large files and binary content
+	if len(content) > 100000 || helper.ContainsBinaryData(content) {
+		return nil, nil
+	}
+
+	// Get relative path
+	relPath, err := filepath.Rel(repoPath, path)
+	if err != nil {
+		relPath = path
+	}
+
+	var processed, total int
+	tracker.update(fmt.Sprintf("Processing %s", relPath), func(progress *models.IndexProgress) {
+		progress.CurrentFile++
+		processed, total = progress.CurrentFile, progress.TotalFiles
 	})
+	log.Printf("📄 Processing file %d/%d: %s", processed, total, relPath)
 
-	log.Printf("📈 Processing completed:")
-	log.Printf("   - Files processed: %d", fileCount)
-	log.Printf("   - Files skipped: %d", skippedFiles)
-	log.Printf("   - Total chunks created: %d", chunkCount)
+	// Queue file chunks; full batches are embedded and stored on the way
+	chunks, err := queueFileChunks(pipeline, string(content), relPath, repoURL, branch, opts)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("⚠️  Failed to process file %s: %v", relPath, err)
+		return nil, nil // Skip files that fail processing
+	}
+
+	log.Printf("✅ Queued %s (%d chunks)", relPath, chunks)
+	tracker.update(fmt.Sprintf("Processed %s (%d chunks)", relPath, chunks), func(progress *models.IndexProgress) {
+		progress.TotalChunks += chunks
+	})
+
+	return &domain.IndexedFile{
+		Repository: helper.ExtractRepoName(repoURL),
+		Branch:     branch,
+		Path:       filepath.ToSlash(relPath),
+		Language:   helper.GetLanguageFromExtension(filepath.Ext(relPath)),
+		Content:    string(content),
+		Size:       len(content),
+		IndexedAt:  indexedAt,
+		Private:    opts.Private,
+	}, nil
+}
 
-	return fileCount, chunkCount, err
+// progressTracker serialises progress updates from concurrent workers so
+// counts stay accurate and reports arrive in order
+type progressTracker struct {
+	mu         sync.Mutex
+	progress   models.IndexProgress
+	onProgress ProgressFunc
 }
 
-// processFile processes a single file and stores chunks
-func processFile(content, filePath, repoURL, branch string) (int, error) {
-	if database.DB == nil {
-		return 0, fmt.Errorf("database not initialized")
+// update applies apply to the progress and reports it with message. An
+// empty message keeps the one set by apply.
+func (t *progressTracker) update(message string, apply func(progress *models.IndexProgress)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if apply != nil {
+		apply(&t.progress)
+	}
+	if message != "" {
+		t.progress.Message = message
+	}
+	if t.onProgress != nil {
+		t.onProgress(t.progress)
 	}
+}
 
+// queueFileChunks splits a file into chunks and queues them in the
+// pipeline, returning the number of chunks
+func queueFileChunks(pipeline *indexPipeline, content, filePath, repoURL, branch string, opts ProcessOptions) (int, error) {
 	// Extract repository name from URL
 	repoName := helper.ExtractRepoName(repoURL)
 
@@ -149,107 +463,69 @@ func processFile(content, filePath, repoURL, branch string) (int, error) {
 	language := helper.GetLanguageFromExtension(filepath.Ext(filePath))
 
 	// Split content into chunks
-	chunks := helper.SplitIntoChunks(content, 1000)
+	chunks := splitFile(content, filePath, language, opts.Chunking)
 	log.Printf("   📝 Split into %d chunks", len(chunks))
 
-	index, err := database.DB.PineconeClient.Index(pinecone.NewIndexConnParams{
-		Host: database.DB.Config.PineconeHost,
-	})
-	if err != nil {
-		return 0, fmt.Errorf("failed to connect to index: %w", err)
-	}
-
-	successfulChunks := 0
-	// Process each chunk
+	pending := make([]pendingChunk, 0, len(chunks))
 	for i, chunk := range chunks {
-		// Get embedding
-		embedding, err := getEmbedding(chunk)
-		if err != nil {
-			log.Printf("   ⚠️  Failed to generate embedding for chunk %d: %v", i+1, err)
-			continue // Skip chunks that fail embedding
-		}
-
 		// Create metadata
-		metadata, err := structpb.NewStruct(map[string]interface{}{
-			"content":    chunk,
+		fields := map[string]interface{}{
+			"content":    chunk.Content,
 			"filePath":   filePath,
 			"repository": repoName,
 			"branch":     branch,
 			"language":   language,
-		})
-		if err != nil {
-			log.Printf("   ⚠️  Failed to create metadata for chunk %d: %v", i+1, err)
-			continue
+			"startLine":  chunk.StartLine,
+			"endLine":    chunk.EndLine,
+			"chunkIndex": i,
 		}
-
-		// Create vector ID
-		vectorID := fmt.Sprintf("%s-%s-%d", repoName, strings.ReplaceAll(filePath, "/", "-"), i)
-		if len(vectorID) > 100 {
-			vectorID = vectorID[:100]
+		if opts.CommitSHA != "" {
+			fields["commitSha"] = opts.CommitSHA
 		}
-
-		// Store in Pinecone
-		vectors := []*pinecone.Vector{
-			{
-				Id:       vectorID,
-				Values:   embedding,
-				Metadata: metadata,
-			},
+		if opts.Private {
+			fields["private"] = true
+		}
+		if chunk.Package != "" {
+			fields["package"] = chunk.Package
+		}
+		if chunk.Receiver != "" {
+			fields["receiver"] = chunk.Receiver
+		}
+		if chunk.Symbol != "" {
+			fields["symbol"] = chunk.Symbol
 		}
 
-		_, err = index.UpsertVectors(context.Background(), vectors)
+		metadata, err := structpb.NewStruct(fields)
 		if err != nil {
-			log.Printf("   ⚠️  Failed to store chunk %d in Pinecone: %v", i+1, err)
-			continue // Skip chunks that fail to store
+			return 0, fmt.Errorf("failed to create metadata for chunk %d: %w", i+1, err)
 		}
 
-		successfulChunks++
-	}
-
-	log.Printf("   💾 Successfully stored %d/%d chunks in Pinecone", successfulChunks, len(chunks))
-	return successfulChunks, nil
-}
-
-// getEmbedding generates embedding for text
-func getEmbedding(text string) ([]float32, error) {
-	if database.DB == nil {
-		return nil, fmt.Errorf("database not initialized")
-	}
-
-	resp, err := database.DB.OpenAIClient.CreateEmbeddings(
-		context.Background(),
-		openai.EmbeddingRequest{
-			Model: openai.AdaEmbeddingV2,
-			Input: []string{text},
-		},
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(resp.Data) == 0 {
-		return nil, fmt.Errorf("no embeddings returned")
+		pending = append(pending, pendingChunk{
+			id:       VectorID(repoName, branch, filePath, i),
+			filePath: filePath,
+			content:  chunk.Content,
+			metadata: metadata,
+		})
 	}
 
-	// Convert []float64 to []float32
-	embedding := make([]float32, len(resp.Data[0].Embedding))
-	for i, v := range resp.Data[0].Embedding {
-		embedding[i] = float32(v)
+	for _, chunk := range pending {
+		if err := pipeline.add(chunk); err != nil {
+			return 0, err
+		}
 	}
 
-	return embedding, nil
+	return len(chunks), nil
 }
 
-// GetRepositoryInfo retrieves information about indexed repositories
-func GetRepositoryInfo(userID string) ([]domain.Repository, error) {
-	// In a real implementation, this would query a database
-	// For now, return empty slice as this is a demo
-	return []domain.Repository{}, nil
-}
+// splitFile splits a file with the chunker for its language, falling back
+// to sliding windows when a language-aware chunker cannot handle the file
+func splitFile(content, filePath, language string, chunking chunker.Options) []domain.Chunk {
+	chunks, err := chunker.For(language, chunking).Chunk(content)
+	if err == nil {
+		return chunks
+	}
+	log.Printf("   ⚠️  Failed to chunk %s by %s structure, using windows: %v", filePath, language, err)
 
-// SaveRepositoryInfo saves repository indexing information
-func SaveRepositoryInfo(repo domain.Repository) error {
-	// In a real implementation, this would save to a database
-	// For now, just return nil as this is a demo
-	return nil
+	chunks, _ = chunker.NewWindowChunker(chunker.Options{Size: chunking.Size, Overlap: chunking.Overlap}).Chunk(content)
+	return chunks
 }
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCollectFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		".gitignore":             "*.log\nbuild/\n",
+		".mcpignore":             "secret.txt\n",
+		".env":                   "TOKEN=x",
+		".git/config":            "[core]",
+		".gitlab/ci.yml":         "stages: []",
+		"main.go":                "package main",
+		"app.log":                "log",
+		"secret.txt":             "secret",
+		"logo.png":               "png",
+		"build/out.go":           "package build",
+		"sub/.gitignore":         "!keep.log\nlocal.go\n",
+		"sub/keep.log":           "log",
+		"sub/local.go":           "package sub",
+		"sub/sub.go":             "package sub",
+		"vendor/lib/lib.go":      "package lib",
+		"vendor/lib/lib_test.go": "package lib",
+	})
+
+	tests := []struct {
+		name    string
+		opts    ProcessOptions
+		include func(relPath string) bool
+		want    []string
+	}{
+		{
+			name: "ignore files",
+			want: []string{".gitlab/ci.yml", "main.go", "sub/keep.log", "sub/sub.go", "vendor/lib/lib.go", "vendor/lib/lib_test.go"},
+		},
+		{
+			name: "include glob",
+			opts: ProcessOptions{Include: []string{"*.go"}},
+			want: []string{"main.go", "sub/sub.go", "vendor/lib/lib.go", "vendor/lib/lib_test.go"},
+		},
+		{
+			name: "exclude wins over include",
+			opts: ProcessOptions{Include: []string{"*.go"}, Exclude: []string{"vendor/**", "*_test.go"}},
+			want: []string{"main.go", "sub/sub.go"},
+		},
+		{
+			name: "excluded directory",
+			opts: ProcessOptions{Exclude: []string{"sub"}},
+			want: []string{".gitlab/ci.yml", "main.go", "vendor/lib/lib.go", "vendor/lib/lib_test.go"},
+		},
+		{
+			name:    "requested files",
+			include: func(relPath string) bool { return relPath == "main.go" || relPath == "app.log" },
+			want:    []string{"main.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := collectFiles(context.Background(), dir, tt.include, tt.opts)
+			if err != nil {
+				t.Fatalf("collectFiles() error = %v", err)
+			}
+
+			var got []string
+			for _, path := range paths {
+				relPath, err := filepath.Rel(dir, path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, filepath.ToSlash(relPath))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectFiles() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"mcp-go-server/helper"
+	"mcp-go-server/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// finishedJobRetention is how long completed jobs stay queryable
+const finishedJobRetention = 24 * time.Hour
+
+var (
+	jobsMu   sync.RWMutex
+	jobStore = make(map[string]*models.IndexJob) // In-memory store (replace with DB in production)
+)
+
+// CreateIndexJob stores a new queued job. It fails with
+// models.ErrIndexingInProgress if the repository branch is already being
+// indexed by another job.
+func CreateIndexJob(userID, repoURL, branch string) (models.IndexJob, error) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	pruneFinishedJobs()
+
+	repoName := helper.ExtractRepoName(repoURL)
+	for _, job := range jobStore {
+		if job.Progress.Repository == repoName && job.Branch == branch && !isJobFinished(job) {
+			return models.IndexJob{}, models.ErrIndexingInProgress
+		}
+	}
+
+	job := &models.IndexJob{
+		ID:      newJobID(),
+		UserID:  userID,
+		RepoURL: repoURL,
+		Branch:  branch,
+		Progress: models.IndexProgress{
+			Repository: repoName,
+			Branch:     branch,
+			Status:     "queued",
+			Message:    "Waiting to start",
+			StartTime:  time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+	jobStore[job.ID] = job
+	return *job, nil
+}
+
+// HasActiveIndexJob reports whether a repository branch, or any branch of
+// the repository when branch is empty, is being indexed
+func HasActiveIndexJob(repository, branch string) bool {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	for _, job := range jobStore {
+		if job.Progress.Repository == repository && (branch == "" || job.Branch == branch) && !isJobFinished(job) {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateIndexJobProgress records the latest progress of a job
+func UpdateIndexJobProgress(jobID string, progress models.IndexProgress) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	if job, exists := jobStore[jobID]; exists {
+		// Keep the original start time across stage reports
+		progress.StartTime = job.Progress.StartTime
+		job.Progress = progress
+	}
+}
+
+// FinishIndexJob records the outcome of a job
+func FinishIndexJob(jobID string, result *models.IndexResponse, jobErr error) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	job, exists := jobStore[jobID]
+	if !exists {
+		return
+	}
+
+	job.Result = result
+	job.Progress.EndTime = time.Now().UTC().Format(time.RFC3339)
+	switch {
+	case errors.Is(jobErr, models.ErrIndexingCancelled):
+		job.Progress.Status = "cancelled"
+		job.Error = jobErr.Error()
+	case jobErr != nil:
+		job.Progress.Status = "failed"
+		job.Progress.Message = jobErr.Error()
+		job.Error = jobErr.Error()
+	default:
+		job.Progress.Status = "completed"
+	}
+}
+
+// GetIndexJob retrieves a job owned by userID
+func GetIndexJob(userID, jobID string) (models.IndexJob, error) {
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+
+	job, exists := jobStore[jobID]
+	if !exists || job.UserID != userID {
+		return models.IndexJob{}, models.ErrJobNotFound
+	}
+	return *job, nil
+}
+
+// ListIndexJobs returns the jobs of a user, newest first
+func ListIndexJobs(userID string) []models.IndexJob {
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+
+	jobs := []models.IndexJob{}
+	for _, job := range jobStore {
+		if job.UserID == userID {
+			jobs = append(jobs, *job)
+		}
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Progress.StartTime > jobs[j].Progress.StartTime
+	})
+	return jobs
+}
+
+func isJobFinished(job *models.IndexJob) bool {
+	return job.Progress.EndTime != ""
+}
+
+// pruneFinishedJobs drops finished jobs past their retention. Callers must
+// hold jobsMu.
+func pruneFinishedJobs() {
+	cutoff := time.Now().Add(-finishedJobRetention)
+	for id, job := range jobStore {
+		if !isJobFinished(job) {
+			continue
+		}
+		if endTime, err := time.Parse(time.RFC3339, job.Progress.EndTime); err == nil && endTime.Before(cutoff) {
+			delete(jobStore, id)
+		}
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic("failed to generate job ID: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pinecone-io/go-pinecone/pinecone"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// migrationBatchSize bounds the vectors listed and fetched per request
+const migrationBatchSize = 100
+
+// deleteIDBatchSize is the maximum number of IDs Pinecone deletes per request
+const deleteIDBatchSize = 1000
+
+// vectorIDRegex matches IDs built by VectorID
+var vectorIDRegex = regexp.MustCompile(`#[0-9a-f]{32}$`)
+
+// legacyVector is a vector stored under a pre-hash "repo-path-i" ID
+type legacyVector struct {
+	id        string
+	startLine int
+	ordinal   int // Parsed from the ID suffix, -1 when it was truncated away
+}
+
+// migrationTarget is the new ID and chunk ordinal of a legacy vector
+type migrationTarget struct {
+	id      string
+	ordinal int
+}
+
+// MigrateVectorIDs rewrites every vector stored under a legacy
+// "repo-path-with-dashes-i" ID to the ID returned by VectorID. Chunk ordinals
+// are reconstructed per file from the line ranges and the old ID suffixes.
+//
+// All copies are stored before any legacy vector is deleted, and legacy
+// vectors are deleted a whole file at a time, so an interrupted run can
+// simply be repeated and assigns the same IDs again. Vectors that already
+// have a hashed ID are left alone. It returns the number of rewritten
+// vectors. Listing vectors requires a serverless index, and no indexing jobs
+// should run meanwhile.
+func MigrateVectorIDs(ctx context.Context) (int, error) {
+	index, err := connectIndex()
+	if err != nil {
+		return 0, err
+	}
+	defer index.Close()
+
+	// First pass: group legacy vectors by file without holding their values
+	files := make(map[[3]string][]legacyVector)
+	skipped := 0
+	err = listVectorIDs(ctx, index, "", func(ids []string) error {
+		var legacyIDs []string
+		for _, id := range ids {
+			if !vectorIDRegex.MatchString(id) {
+				legacyIDs = append(legacyIDs, id)
+			}
+		}
+		if len(legacyIDs) == 0 {
+			return nil
+		}
+
+		fetched, err := index.FetchVectors(ctx, legacyIDs)
+		if err != nil {
+			return fmt.Errorf("failed to fetch vectors: %w", err)
+		}
+		for id, vector := range fetched.Vectors {
+			if vector.Metadata == nil {
+				skipped++
+				continue
+			}
+			metadata := vector.Metadata.AsMap()
+			key := [3]string{metadataString(metadata, "repository"), metadataString(metadata, "branch"), metadataString(metadata, "filePath")}
+			if key[0] == "" || key[2] == "" {
+				skipped++
+				continue
+			}
+			files[key] = append(files[key], legacyVector{id: id, startLine: metadataInt(metadata, "startLine"), ordinal: legacyOrdinal(id)})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if skipped > 0 {
+		log.Printf("⚠️  Skipping %d vectors without repository or file metadata", skipped)
+	}
+
+	// Assign ordinals in file order
+	targets := make(map[string]migrationTarget)
+	for key, vectors := range files {
+		sort.Slice(vectors, func(i, j int) bool {
+			if vectors[i].startLine != vectors[j].startLine {
+				return vectors[i].startLine < vectors[j].startLine
+			}
+			if vectors[i].ordinal != vectors[j].ordinal {
+				return vectors[i].ordinal < vectors[j].ordinal
+			}
+			return vectors[i].id < vectors[j].id
+		})
+		for ordinal, vector := range vectors {
+			targets[vector.id] = migrationTarget{id: VectorID(key[0], key[1], key[2], ordinal), ordinal: ordinal}
+		}
+	}
+	log.Printf("🔁 Migrating %d vectors of %d files to hashed IDs", len(targets), len(files))
+
+	// Second pass: copy each vector to its new ID
+	oldIDs := make([]string, 0, len(targets))
+	for id := range targets {
+		oldIDs = append(oldIDs, id)
+	}
+	sort.Strings(oldIDs)
+
+	migrated := 0
+	for start := 0; start < len(oldIDs); start += migrationBatchSize {
+		end := start + migrationBatchSize
+		if end > len(oldIDs) {
+			end = len(oldIDs)
+		}
+
+		n, err := migrateVectorBatch(ctx, index, oldIDs[start:end], targets)
+		migrated += n
+		if err != nil {
+			return migrated, err
+		}
+		log.Printf("   ✅ Copied %d/%d vectors", migrated, len(oldIDs))
+	}
+
+	// Third pass: drop the legacy vectors file by file
+	for key, vectors := range files {
+		ids := make([]string, 0, len(vectors))
+		for _, vector := range vectors {
+			ids = append(ids, vector.id)
+		}
+		for start := 0; start < len(ids); start += deleteIDBatchSize {
+			end := start + deleteIDBatchSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+			if err := index.DeleteVectorsById(ctx, ids[start:end]); err != nil {
+				return migrated, fmt.Errorf("failed to delete legacy vectors of %s: %w", key[2], err)
+			}
+		}
+	}
+	log.Printf("🗑️  Deleted %d legacy vectors", len(oldIDs))
+
+	return migrated, nil
+}
+
+// migrateVectorBatch stores a batch of vectors under their new IDs and
+// returns how many were copied
+func migrateVectorBatch(ctx context.Context, index *pinecone.IndexConnection, oldIDs []string, targets map[string]migrationTarget) (int, error) {
+	fetched, err := index.FetchVectors(ctx, oldIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch vectors: %w", err)
+	}
+
+	var batch []*pinecone.Vector
+	batchBytes, migrated := 0, 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := index.UpsertVectors(ctx, batch); err != nil {
+			return fmt.Errorf("failed to upsert migrated vectors: %w", err)
+		}
+		migrated += len(batch)
+		batch, batchBytes = nil, 0
+		return nil
+	}
+
+	for _, oldID := range oldIDs {
+		vector, ok := fetched.Vectors[oldID]
+		if !ok {
+			continue // Deleted since it was listed
+		}
+
+		target := targets[oldID]
+		metadata := vector.Metadata.AsMap()
+		metadata["chunkIndex"] = target.ordinal
+		fields, err := structpb.NewStruct(metadata)
+		if err != nil {
+			return migrated, fmt.Errorf("failed to rebuild metadata of %s: %w", oldID, err)
+		}
+		migratedVector := &pinecone.Vector{
+			Id:           target.id,
+			Values:       vector.Values,
+			SparseValues: vector.SparseValues,
+			Metadata:     fields,
+		}
+
+		size := vectorSize(migratedVector)
+		if len(batch) > 0 && batchBytes+size > maxUpsertBatchBytes {
+			if err := flush(); err != nil {
+				return migrated, err
+			}
+		}
+		batch = append(batch, migratedVector)
+		batchBytes += size
+	}
+
+	err = flush()
+	return migrated, err
+}
+
+// listVectorIDs pages through the vector IDs of the index starting with
+// prefix, or every vector ID when prefix is empty
+func listVectorIDs(ctx context.Context, index *pinecone.IndexConnection, prefix string, handle func(ids []string) error) error {
+	limit := uint32(migrationBatchSize)
+	req := &pinecone.ListVectorsRequest{Limit: &limit}
+	if prefix != "" {
+		req.Prefix = &prefix
+	}
+	for {
+		page, err := index.ListVectors(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to list vectors: %w", err)
+		}
+
+		ids := make([]string, 0, len(page.VectorIds))
+		for _, id := range page.VectorIds {
+			if id != nil {
+				ids = append(ids, *id)
+			}
+		}
+		if err := handle(ids); err != nil {
+			return err
+		}
+
+		if page.NextPaginationToken == nil || *page.NextPaginationToken == "" {
+			return nil
+		}
+		req.PaginationToken = page.NextPaginationToken
+	}
+}
+
+// legacyOrdinal parses the chunk number from the "-i" suffix of a legacy ID.
+// IDs truncated to 100 characters may have lost it, giving -1.
+func legacyOrdinal(id string) int {
+	if len(id) >= 100 {
+		return -1
+	}
+	dash := strings.LastIndex(id, "-")
+	ordinal, err := strconv.Atoi(id[dash+1:])
+	if dash < 0 || err != nil {
+		return -1
+	}
+	return ordinal
+}
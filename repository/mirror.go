@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mcp-go-server/database"
+	"mcp-go-server/helper"
+	"mcp-go-server/models"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mirror guards a bare mirror in the cache. Fetches and worktree changes
+// hold mu; users counts the checkouts still in use so the mirror is never
+// evicted underneath them. The cache may be shared by several server
+// processes, so the same work is also guarded by file locks: an exclusive
+// lock on the mirror's lock file around changes, and a shared lock on its
+// use file while a checkout is in use.
+type mirror struct {
+	mu    sync.Mutex
+	users int
+}
+
+var (
+	mirrorsMu sync.Mutex
+	mirrors   = make(map[string]*mirror)
+)
+
+// mirrorCacheConfig returns the mirror cache directory and size limit. The
+// cache is disabled when the limit is 0.
+func mirrorCacheConfig() (string, int64, bool) {
+	if database.DB == nil || database.DB.Config == nil {
+		return "", 0, false
+	}
+	cfg := database.DB.Config
+	return cfg.RepoCacheDir, cfg.RepoCacheMaxBytes, cfg.RepoCacheDir != "" && cfg.RepoCacheMaxBytes > 0
+}
+
+// mirrorDir returns the cache directory of the mirror of repoURL, named after
+// the repository and a hash of its URL
+func mirrorDir(cacheDir, repoURL string) string {
+	sum := sha256.Sum256([]byte(repoURL))
+	name := strings.ReplaceAll(helper.ExtractRepoName(repoURL), "/", "-")
+	return filepath.Join(cacheDir, fmt.Sprintf("%s-%s.git", name, hex.EncodeToString(sum[:8])))
+}
+
+// mirrorLockPath returns the lock file guarding changes to the mirror at dir
+func mirrorLockPath(dir string) string {
+	return dir + ".lock"
+}
+
+// mirrorUsePath returns the lock file held shared while checkouts of the
+// mirror at dir are in use
+func mirrorUsePath(dir string) string {
+	return dir + ".use"
+}
+
+// acquireMirror registers a user of the mirror at dir
+func acquireMirror(dir string) *mirror {
+	mirrorsMu.Lock()
+	defer mirrorsMu.Unlock()
+
+	m, ok := mirrors[dir]
+	if !ok {
+		m = &mirror{}
+		mirrors[dir] = m
+	}
+	m.users++
+	return m
+}
+
+// releaseMirror unregisters a user of the mirror at dir and marks it as
+// recently used
+func releaseMirror(dir string, m *mirror) {
+	mirrorsMu.Lock()
+	defer mirrorsMu.Unlock()
+
+	m.users--
+	now := time.Now()
+	os.Chtimes(dir, now, now)
+}
+
+// checkoutFromMirror updates the cached mirror of repoURL and checks out
+// branch into a temporary worktree. The returned release function removes
+// the worktree.
+func checkoutFromMirror(ctx context.Context, cacheDir string, maxBytes int64, repoURL, branch string) (string, func(), error) {
+	dir := mirrorDir(cacheDir, repoURL)
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("failed to create mirror cache directory: %w", err)
+	}
+	unlockUse, err := lockFile(mirrorUsePath(dir), true, true)
+	if err != nil {
+		return "", nil, err
+	}
+	m := acquireMirror(dir)
+
+	worktreeDir, err := func() (string, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		unlock, err := lockFile(mirrorLockPath(dir), false, true)
+		if err != nil {
+			return "", err
+		}
+		defer unlock()
+
+		if err := updateMirror(ctx, dir, repoURL); err != nil {
+			return "", err
+		}
+
+		// Branches take precedence over tags of the same name, as with git clone --branch
+		commit := ""
+		for _, ref := range []string{"refs/heads/" + branch, "refs/tags/" + branch} {
+			if output, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err == nil {
+				commit = strings.TrimSpace(string(output))
+				break
+			}
+		}
+		if commit == "" {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			return "", fmt.Errorf("%w: %s", models.ErrRefNotFound, branch)
+		}
+
+		tempDir, err := ioutil.TempDir("", "repo-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		worktreeDir := filepath.Join(tempDir, "worktree")
+		if _, err := runGit(ctx, dir, "worktree", "add", "--detach", worktreeDir, commit); err != nil {
+			os.RemoveAll(tempDir)
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			return "", fmt.Errorf("failed to create worktree: %w", err)
+		}
+		return worktreeDir, nil
+	}()
+	if err != nil {
+		releaseMirror(dir, m)
+		unlockUse()
+		return "", nil, err
+	}
+	log.Printf("🌳 Checked out %s of %s into worktree %s", branch, repoURL, worktreeDir)
+
+	evictMirrors(cacheDir, maxBytes)
+
+	release := func() {
+		m.mu.Lock()
+		if unlock, err := lockFile(mirrorLockPath(dir), false, true); err != nil {
+			log.Printf("⚠️  Failed to lock mirror %s: %v", dir, err)
+		} else {
+			if _, err := runGit(context.Background(), dir, "worktree", "remove", "--force", worktreeDir); err != nil {
+				log.Printf("⚠️  Failed to remove worktree %s: %v", worktreeDir, err)
+			}
+			unlock()
+		}
+		m.mu.Unlock()
+		os.RemoveAll(filepath.Dir(worktreeDir))
+		releaseMirror(dir, m)
+		unlockUse()
+	}
+	return worktreeDir, release, nil
+}
+
+// updateMirror creates the bare mirror of repoURL at dir, or fetches into it
+// when it already exists. The caller must hold the mirror's locks.
+func updateMirror(ctx context.Context, dir, repoURL string) error {
+	if _, err := os.Stat(filepath.Join(dir, "HEAD")); err == nil {
+		log.Printf("🔄 Fetching cached mirror %s", dir)
+		// Drop worktrees left behind by an interrupted run
+		runGit(ctx, dir, "worktree", "prune")
+		if _, err := runGit(ctx, dir, "fetch", "--prune", "--", repoURL, "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*"); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to fetch repository: %w", err)
+		}
+		return nil
+	}
+
+	log.Printf("🪞 Creating mirror of %s in %s", repoURL, dir)
+	os.RemoveAll(dir) // Leftovers of an interrupted clone
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return fmt.Errorf("failed to create mirror cache directory: %w", err)
+	}
+	if _, err := runGit(ctx, "", "clone", "--bare", "--", repoURL, dir); err != nil {
+		os.RemoveAll(dir)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("failed to clone repository: %w", err)
+	}
+	return nil
+}
+
+// evictMirrors removes the least recently used mirrors until the cache fits
+// in maxBytes. Mirrors with a checkout in use, in this or another process,
+// are kept.
+func evictMirrors(cacheDir string, maxBytes int64) {
+	entries, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		log.Printf("⚠️  Failed to read mirror cache: %v", err)
+		return
+	}
+
+	type cachedMirror struct {
+		dir      string
+		size     int64
+		lastUsed time.Time
+	}
+	var cached []cachedMirror
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(cacheDir, entry.Name())
+		size := dirSize(dir)
+		cached = append(cached, cachedMirror{dir: dir, size: size, lastUsed: entry.ModTime()})
+		total += size
+	}
+	if total <= maxBytes {
+		return
+	}
+
+	sort.Slice(cached, func(i, j int) bool {
+		return cached[i].lastUsed.Before(cached[j].lastUsed)
+	})
+
+	mirrorsMu.Lock()
+	defer mirrorsMu.Unlock()
+	for _, c := range cached {
+		if total <= maxBytes {
+			break
+		}
+		if m, ok := mirrors[c.dir]; ok && m.users > 0 {
+			continue
+		}
+		if !evictMirror(c.dir, c.size) {
+			continue
+		}
+		delete(mirrors, c.dir)
+		total -= c.size
+	}
+}
+
+// evictMirror removes the mirror at dir unless another process is using or
+// changing it
+func evictMirror(dir string, size int64) bool {
+	unlockUse, err := lockFile(mirrorUsePath(dir), false, false)
+	if err != nil {
+		return false
+	}
+	defer unlockUse()
+	unlock, err := lockFile(mirrorLockPath(dir), false, false)
+	if err != nil {
+		return false
+	}
+	defer unlock()
+
+	log.Printf("🧹 Evicting cached mirror %s (%d bytes)", dir, size)
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf("⚠️  Failed to evict mirror %s: %v", dir, err)
+		return false
+	}
+	return true
+}
+
+// dirSize returns the total size of the regular files below dir
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
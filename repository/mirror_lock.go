@@ -0,0 +1,42 @@
+//go:build unix
+
+package repository
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an flock on path, creating the file if needed. Shared locks
+// can be held by several processes at once; with wait unset, a lock held
+// elsewhere fails immediately. The returned function releases the lock.
+func lockFile(path string, shared, wait bool) (func(), error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	how := syscall.LOCK_EX
+	if shared {
+		how = syscall.LOCK_SH
+	}
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), how)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package repository
+
+// lockFile is a no-op on platforms without flock; mirrors are then only
+// guarded against other jobs of the same process
+func lockFile(path string, shared, wait bool) (func(), error) {
+	return func() {}, nil
+}
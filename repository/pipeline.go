@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mcp-go-server/database"
+	"mcp-go-server/models"
+	"net/http"
+	"strings"
+
+	"github.com/pinecone-io/go-pinecone/pinecone"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Limits of a single embeddings request and Pinecone upsert
+const (
+	maxEmbeddingBatchInputs = 2048        // OpenAI accepts at most 2048 inputs per request
+	maxEmbeddingBatchTokens = 250000      // Stays below OpenAI's 300k tokens per request
+	maxEmbeddingInputTokens = 8191        // text-embedding-ada-002 accepts at most 8191 tokens per input
+	maxUpsertBatchVectors   = 100         // Pinecone's recommended upsert batch size
+	maxUpsertBatchBytes     = 1536 * 1024 // Stays below Pinecone's 2MB request limit
+)
+
+// pendingChunk is a chunk waiting to be embedded and stored
+type pendingChunk struct {
+	id       string
+	filePath string
+	content  string
+	metadata *structpb.Struct
+}
+
+// indexPipeline embeds chunks in multi-input requests bounded by the
+// embedding model's limits and stores the vectors in size-bounded upsert
+// batches. Failed batches are recorded rather than aborting the run.
+type indexPipeline struct {
+	ctx      context.Context
+	index    *pinecone.IndexConnection
+	onStored func(count int)
+
+	// embed and upsert send the requests; tests replace them
+	embed  func(ctx context.Context, texts []string) ([][]float32, error)
+	upsert func(ctx context.Context, vectors []*pinecone.Vector) (uint32, error)
+
+	embedQueue  []pendingChunk
+	embedTokens int
+
+	upsertQueue []*pinecone.Vector
+	upsertPaths []string
+	upsertBytes int
+
+	stored   map[string]int
+	failures []models.BatchFailure
+}
+
+// newIndexPipeline connects to the index. onStored receives the number of
+// chunks of every successfully stored batch.
+func newIndexPipeline(ctx context.Context, onStored func(count int)) (*indexPipeline, error) {
+	index, err := connectIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	return &indexPipeline{
+		ctx:      ctx,
+		index:    index,
+		onStored: onStored,
+		embed:    getEmbeddings,
+		upsert:   index.UpsertVectors,
+		stored:   make(map[string]int),
+	}, nil
+}
+
+// add queues a chunk, sending the pending embeddings batch first if the
+// chunk would push it over the request limits. Chunks over the model's
+// per-input limit are embedded from their leading part only; the stored
+// content stays complete.
+func (p *indexPipeline) add(chunk pendingChunk) error {
+	if estimateTokens(chunk.content) > maxEmbeddingInputTokens {
+		log.Printf("   ✂️  Truncating oversized chunk of %s (%d bytes) for embedding", chunk.filePath, len(chunk.content))
+		chunk.content = truncateForEmbedding(chunk.content)
+	}
+
+	tokens := estimateTokens(chunk.content)
+	if len(p.embedQueue) > 0 && (len(p.embedQueue) >= maxEmbeddingBatchInputs || p.embedTokens+tokens > maxEmbeddingBatchTokens) {
+		if err := p.flushEmbeddings(); err != nil {
+			return err
+		}
+	}
+
+	p.embedQueue = append(p.embedQueue, chunk)
+	p.embedTokens += tokens
+	return nil
+}
+
+// flush embeds and stores everything still queued
+func (p *indexPipeline) flush() error {
+	if err := p.flushEmbeddings(); err != nil {
+		return err
+	}
+	return p.flushUpserts()
+}
+
+// close releases the index connection
+func (p *indexPipeline) close() {
+	p.index.Close()
+}
+
+// storedChunks returns the number of chunks stored for a file
+func (p *indexPipeline) storedChunks(filePath string) int {
+	return p.stored[filePath]
+}
+
+// flushEmbeddings embeds the queued chunks and moves the resulting vectors
+// to the upsert queue
+func (p *indexPipeline) flushEmbeddings() error {
+	if len(p.embedQueue) == 0 {
+		return nil
+	}
+
+	batch := p.embedQueue
+	p.embedQueue = nil
+	p.embedTokens = 0
+	return p.embedBatch(batch)
+}
+
+// embedBatch embeds chunks in one request. Batches the API rejects for
+// exceeding a token limit are split in half and retried, so only a single
+// chunk the model still refuses is recorded as a failure.
+func (p *indexPipeline) embedBatch(batch []pendingChunk) error {
+	texts := make([]string, len(batch))
+	paths := make([]string, len(batch))
+	for i, chunk := range batch {
+		texts[i] = chunk.content
+		paths[i] = chunk.filePath
+	}
+
+	log.Printf("   🧠 Generating embeddings for %d chunks", len(batch))
+	embeddings, err := p.embed(p.ctx, texts)
+	if err != nil {
+		if p.ctx.Err() != nil {
+			return p.ctx.Err()
+		}
+		if len(batch) > 1 && isTokenLimitError(err) {
+			log.Printf("   ✂️  Embedding batch exceeds the token limit, retrying in halves: %v", err)
+			half := len(batch) / 2
+			if err := p.embedBatch(batch[:half]); err != nil {
+				return err
+			}
+			return p.embedBatch(batch[half:])
+		}
+		p.recordFailure("embedding", paths, err)
+		return nil
+	}
+
+	for i, chunk := range batch {
+		vector := &pinecone.Vector{
+			Id:       chunk.id,
+			Values:   embeddings[i],
+			Metadata: chunk.metadata,
+		}
+
+		size := vectorSize(vector)
+		if len(p.upsertQueue) > 0 && (len(p.upsertQueue) >= maxUpsertBatchVectors || p.upsertBytes+size > maxUpsertBatchBytes) {
+			if err := p.flushUpserts(); err != nil {
+				return err
+			}
+		}
+
+		p.upsertQueue = append(p.upsertQueue, vector)
+		p.upsertPaths = append(p.upsertPaths, chunk.filePath)
+		p.upsertBytes += size
+	}
+
+	return nil
+}
+
+// flushUpserts stores the queued vectors in one upsert request
+func (p *indexPipeline) flushUpserts() error {
+	if len(p.upsertQueue) == 0 {
+		return nil
+	}
+
+	vectors, paths := p.upsertQueue, p.upsertPaths
+	p.upsertQueue, p.upsertPaths = nil, nil
+	p.upsertBytes = 0
+
+	if _, err := p.upsert(p.ctx, vectors); err != nil {
+		if p.ctx.Err() != nil {
+			return p.ctx.Err()
+		}
+		p.recordFailure("upsert", paths, err)
+		return nil
+	}
+
+	for _, path := range paths {
+		p.stored[path]++
+	}
+	log.Printf("   💾 Stored batch of %d chunks in Pinecone", len(vectors))
+	if p.onStored != nil {
+		p.onStored(len(vectors))
+	}
+	return nil
+}
+
+// recordFailure records a failed batch of chunks belonging to paths
+func (p *indexPipeline) recordFailure(stage string, paths []string, err error) {
+	seen := make(map[string]bool)
+	var files []string
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	log.Printf("   ⚠️  Failed %s batch of %d chunks from %d files: %v", stage, len(paths), len(files), err)
+	p.failures = append(p.failures, models.BatchFailure{
+		Stage:  stage,
+		Chunks: len(paths),
+		Files:  files,
+		Error:  err.Error(),
+	})
+}
+
+// estimateTokens returns an upper bound of the token count of text. Every
+// token of the model's byte-level tokenizer covers at least one byte, so
+// this holds for any input, including minified code and non-ASCII text.
+func estimateTokens(text string) int {
+	return len(text)
+}
+
+// truncateForEmbedding cuts text at a character boundary so its estimated
+// token count stays within maxEmbeddingInputTokens
+func truncateForEmbedding(text string) string {
+	if len(text) <= maxEmbeddingInputTokens {
+		return text
+	}
+	return strings.ToValidUTF8(text[:maxEmbeddingInputTokens], "")
+}
+
+// isTokenLimitError reports whether the embeddings API rejected a request
+// for exceeding the model's context length or the per-request token limit
+func isTokenLimitError(err error) bool {
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		return false
+	}
+	return strings.Contains(strings.ToLower(apiErr.Message), "token")
+}
+
+// vectorSize estimates the request size of a vector in bytes
+func vectorSize(vector *pinecone.Vector) int {
+	size := len(vector.Id) + len(vector.Values)*4
+	if vector.Metadata != nil {
+		for key, value := range vector.Metadata.Fields {
+			size += len(key) + len(value.GetStringValue()) + 16
+		}
+	}
+	return size
+}
+
+// getEmbeddings generates embeddings for texts in a single request
+func getEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	if database.DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	resp, err := database.DB.OpenAIClient.CreateEmbeddings(
+		ctx,
+		openai.EmbeddingRequest{
+			Model: openai.AdaEmbeddingV2,
+			Input: texts,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Data))
+	}
+
+	// Order embeddings like the inputs
+	embeddings := make([][]float32, len(texts))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	return embeddings, nil
+}
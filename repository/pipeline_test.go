@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/pinecone-io/go-pinecone/pinecone"
+	"github.com/sashabaranov/go-openai"
+)
+
+// testPipeline returns a pipeline whose requests are recorded instead of
+// sent. embed may be nil to embed every batch successfully.
+func testPipeline(embed func(texts []string) error) (*indexPipeline, *[][]string) {
+	var batches [][]string
+	p := &indexPipeline{
+		ctx:    context.Background(),
+		stored: make(map[string]int),
+	}
+	p.embed = func(ctx context.Context, texts []string) ([][]float32, error) {
+		if embed != nil {
+			if err := embed(texts); err != nil {
+				return nil, err
+			}
+		}
+		batches = append(batches, texts)
+		return make([][]float32, len(texts)), nil
+	}
+	p.upsert = func(ctx context.Context, vectors []*pinecone.Vector) (uint32, error) {
+		return uint32(len(vectors)), nil
+	}
+	return p, &batches
+}
+
+func addChunks(t *testing.T, p *indexPipeline, count int, content string) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		chunk := pendingChunk{id: fmt.Sprintf("id-%d", i), filePath: fmt.Sprintf("file%d.go", i%3), content: content}
+		if err := p.add(chunk); err != nil {
+			t.Fatalf("add() error = %v", err)
+		}
+	}
+	if err := p.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+}
+
+func TestPipelineBatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int
+		content     string
+		wantBatches []int // Number of inputs of every embeddings request
+	}{
+		{name: "single batch", count: 10, content: "func main() {}", wantBatches: []int{10}},
+		{name: "split by input count", count: maxEmbeddingBatchInputs + 5, content: "x", wantBatches: []int{maxEmbeddingBatchInputs, 5}},
+		{
+			name:        "split by tokens",
+			count:       maxEmbeddingBatchTokens/maxEmbeddingInputTokens + 1,
+			content:     strings.Repeat("a", maxEmbeddingInputTokens),
+			wantBatches: []int{maxEmbeddingBatchTokens / maxEmbeddingInputTokens, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, batches := testPipeline(nil)
+			addChunks(t, p, tt.count, tt.content)
+
+			if len(*batches) != len(tt.wantBatches) {
+				t.Fatalf("sent %d embeddings requests, want %d", len(*batches), len(tt.wantBatches))
+			}
+			for i, batch := range *batches {
+				if len(batch) != tt.wantBatches[i] {
+					t.Errorf("request %d has %d inputs, want %d", i, len(batch), tt.wantBatches[i])
+				}
+			}
+
+			stored := 0
+			for _, count := range p.stored {
+				stored += count
+			}
+			if stored != tt.count || len(p.failures) != 0 {
+				t.Errorf("stored %d chunks with failures %+v, want %d stored", stored, p.failures, tt.count)
+			}
+		})
+	}
+}
+
+func TestPipelineTruncatesOversizedChunks(t *testing.T) {
+	p, batches := testPipeline(nil)
+	addChunks(t, p, 1, strings.Repeat("ü", maxEmbeddingInputTokens))
+
+	text := (*batches)[0][0]
+	if estimateTokens(text) > maxEmbeddingInputTokens {
+		t.Errorf("embedded %d estimated tokens, want at most %d", estimateTokens(text), maxEmbeddingInputTokens)
+	}
+	if !utf8.ValidString(text) {
+		t.Error("truncated text is not valid UTF-8")
+	}
+	if !strings.HasPrefix(strings.Repeat("ü", maxEmbeddingInputTokens), text) {
+		t.Error("truncated text is not the leading part of the chunk")
+	}
+}
+
+func TestPipelineRetriesTokenLimitErrors(t *testing.T) {
+	tokenLimit := &openai.APIError{
+		HTTPStatusCode: http.StatusBadRequest,
+		Message:        "This model's maximum context length is 8192 tokens, however you requested 9000 tokens",
+	}
+
+	tests := []struct {
+		name         string
+		embed        func(texts []string) error
+		wantBatches  int
+		wantFailures int
+	}{
+		{
+			name: "batch over the limit is split",
+			embed: func(texts []string) error {
+				if len(texts) > 2 {
+					return tokenLimit
+				}
+				return nil
+			},
+			wantBatches: 4,
+		},
+		{
+			name: "single chunk over the limit fails",
+			embed: func(texts []string) error {
+				for _, text := range texts {
+					if text == "bad" {
+						return tokenLimit
+					}
+				}
+				return nil
+			},
+			wantBatches:  3,
+			wantFailures: 1,
+		},
+		{
+			name: "other errors are not retried",
+			embed: func(texts []string) error {
+				return &openai.APIError{HTTPStatusCode: http.StatusInternalServerError, Message: "server error"}
+			},
+			wantFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, batches := testPipeline(tt.embed)
+			for i, content := range []string{"a", "b", "c", "bad", "d", "e", "f", "g"} {
+				if err := p.add(pendingChunk{id: fmt.Sprint(i), filePath: content + ".go", content: content}); err != nil {
+					t.Fatalf("add() error = %v", err)
+				}
+			}
+			if err := p.flush(); err != nil {
+				t.Fatalf("flush() error = %v", err)
+			}
+
+			if len(*batches) != tt.wantBatches {
+				t.Errorf("embedded %d batches, want %d", len(*batches), tt.wantBatches)
+			}
+			if len(p.failures) != tt.wantFailures {
+				t.Errorf("recorded failures %+v, want %d", p.failures, tt.wantFailures)
+			}
+		})
+	}
+}
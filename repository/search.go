@@ -128,11 +128,15 @@ func SearchVectors(queryEmbedding []float32, repository, branch string, limit in
 		result := domain.SearchResult{
 			CodeChunk: domain.CodeChunk{
 				ID:         match.Vector.Id,
-				Content:    metadata["content"].(string),
-				FilePath:   metadata["filePath"].(string),
-				Repository: metadata["repository"].(string),
-				Branch:     metadata["branch"].(string),
-				Language:   metadata["language"].(string),
+				Content:    metadataString(metadata, "content"),
+				FilePath:   metadataString(metadata, "filePath"),
+				Repository: metadataString(metadata, "repository"),
+				Branch:     metadataString(metadata, "branch"),
+				Language:   metadataString(metadata, "language"),
+				StartLine:  metadataInt(metadata, "startLine"),
+				EndLine:    metadataInt(metadata, "endLine"),
+				CommitSHA:  metadataString(metadata, "commitSha"),
+				Private:    metadataBool(metadata, "private"),
 			},
 			Score: match.Score,
 		}
@@ -143,32 +147,89 @@ func SearchVectors(queryEmbedding []float32, repository, branch string, limit in
 	return results, nil
 }
 
-// GenerateAISummary generates AI summary for search results
-func GenerateAISummary(results []models.SearchResult, query string) (string, error) {
-	if database.DB == nil {
-		return "", fmt.Errorf("database not initialized")
-	}
+// metadataString reads a string metadata field, which is empty for vectors
+// stored before the field was introduced
+func metadataString(metadata map[string]interface{}, key string) string {
+	value, _ := metadata[key].(string)
+	return value
+}
 
-	if len(results) == 0 {
-		return "No results found for the query.", nil
-	}
+// metadataBool reads a boolean metadata field, which is false when unset
+func metadataBool(metadata map[string]interface{}, key string) bool {
+	value, _ := metadata[key].(bool)
+	return value
+}
 
-	// Build context from search results
+// metadataInt reads a numeric metadata field, which Pinecone returns as float64
+func metadataInt(metadata map[string]interface{}, key string) int {
+	value, _ := metadata[key].(float64)
+	return int(value)
+}
+
+// SummarySystemPrompt instructs the model that summarizes search results
+const SummarySystemPrompt = `You are a technical expert analyzing code search results.
+Provide a concise, helpful summary that directly answers the user's query.
+Focus on the most relevant information from the code snippets.
+Be specific and technical when appropriate.`
+
+// summaryContextLimit bounds the search context sent for summaries
+const summaryContextLimit = 3000
+
+// BuildResultsContext formats search results as prompt context, truncating
+// once the context exceeds maxLen characters
+func BuildResultsContext(results []models.SearchResult, maxLen int) string {
 	var contextBuilder strings.Builder
 	contextBuilder.WriteString("Based on the following code search results:\n\n")
 
 	for i, result := range results {
-		contextBuilder.WriteString(fmt.Sprintf("Result %d - File: %s\n", i+1, result.FilePath))
+		if result.StartLine > 0 {
+			contextBuilder.WriteString(fmt.Sprintf("Result %d - File: %s (lines %d-%d)\n", i+1, result.FilePath, result.StartLine, result.EndLine))
+		} else {
+			contextBuilder.WriteString(fmt.Sprintf("Result %d - File: %s\n", i+1, result.FilePath))
+		}
 		contextBuilder.WriteString(fmt.Sprintf("Language: %s\n", result.Language))
 		contextBuilder.WriteString(fmt.Sprintf("Content:\n%s\n\n", result.Content))
 
 		// Limit context size
-		if contextBuilder.Len() > 3000 {
+		if contextBuilder.Len() > maxLen {
 			contextBuilder.WriteString("... (truncated for brevity)\n")
 			break
 		}
 	}
 
+	return contextBuilder.String()
+}
+
+// BuildSummaryPrompt builds the prompt used to summarize search results
+func BuildSummaryPrompt(results []models.SearchResult, query string) models.SummaryPrompt {
+	// Build context from search results
+	searchContext := BuildResultsContext(results, summaryContextLimit)
+
+	return models.SummaryPrompt{
+		SystemPrompt: SummarySystemPrompt,
+		UserPrompt: fmt.Sprintf(`Query: %s
+
+%s
+
+Provide a concise summary that answers the query based on the code search results.`,
+			query, searchContext),
+		Temperature: 0.3,
+		MaxTokens:   300,
+	}
+}
+
+// GenerateAISummary generates AI summary for search results
+func GenerateAISummary(results []models.SearchResult, query string) (string, error) {
+	if database.DB == nil {
+		return "", fmt.Errorf("database not initialized")
+	}
+
+	if len(results) == 0 {
+		return "No results found for the query.", nil
+	}
+
+	prompt := BuildSummaryPrompt(results, query)
+
 	// Generate summary using OpenAI
 	completion, err := database.DB.OpenAIClient.CreateChatCompletion(
 		context.Background(),
@@ -176,24 +237,16 @@ func GenerateAISummary(results []models.SearchResult, query string) (string, err
 			Model: openai.GPT3Dot5Turbo,
 			Messages: []openai.ChatCompletionMessage{
 				{
-					Role: "system",
-					Content: `You are a technical expert analyzing code search results. 
-					Provide a concise, helpful summary that directly answers the user's query.
-					Focus on the most relevant information from the code snippets.
-					Be specific and technical when appropriate.`,
+					Role:    "system",
+					Content: prompt.SystemPrompt,
 				},
 				{
-					Role: "user",
-					Content: fmt.Sprintf(`Query: %s
-
-%s
-
-Provide a concise summary that answers the query based on the code search results.`,
-						query, contextBuilder.String()),
+					Role:    "user",
+					Content: prompt.UserPrompt,
 				},
 			},
-			Temperature: 0.3,
-			MaxTokens:   300,
+			Temperature: prompt.Temperature,
+			MaxTokens:   prompt.MaxTokens,
 		},
 	)
 	if err != nil {
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mcp-go-server/database"
+	"strings"
+
+	"github.com/pinecone-io/go-pinecone/pinecone"
+)
+
+// maxVectorIDPrefix bounds the readable part of a vector ID, keeping IDs
+// well below Pinecone's 512 byte limit
+const maxVectorIDPrefix = 256
+
+// VectorID returns the deterministic ID of the ordinal-th chunk of a file. It
+// consists of a readable "repository#branch#" prefix, a hash of the
+// repository, branch and file path shared by the file's chunks, and a hash
+// that adds the ordinal, so IDs never collide however long or similar the
+// paths are and a file's vectors can be listed by prefix.
+func VectorID(repository, branch, filePath string, ordinal int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", repository, branch, filePath, ordinal)))
+	return fileVectorIDPrefix(repository, branch, filePath) + hex.EncodeToString(sum[:8])
+}
+
+// VectorIDPrefix returns the readable prefix shared by the vector IDs of a
+// repository branch. Overly long prefixes are cut at a character boundary.
+func VectorIDPrefix(repository, branch string) string {
+	return truncateVectorIDPrefix(repository + "#" + branch + "#")
+}
+
+// repositoryVectorIDPrefix returns the prefix shared by the vector IDs of a
+// repository branch, or of every branch when branch is empty. It reports
+// false when the prefix was cut and may be shared with other repositories
+// or branches.
+func repositoryVectorIDPrefix(repository, branch string) (string, bool) {
+	full := repository + "#"
+	if branch != "" {
+		full += branch + "#"
+	}
+	prefix := truncateVectorIDPrefix(full)
+	return prefix, prefix == full
+}
+
+// fileVectorIDPrefix returns the prefix shared by the vector IDs of a file
+func fileVectorIDPrefix(repository, branch, filePath string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s", repository, branch, filePath)))
+	return VectorIDPrefix(repository, branch) + hex.EncodeToString(sum[:8])
+}
+
+// truncateVectorIDPrefix cuts an overly long prefix at a character boundary.
+// The trailing "#" is kept, so IDs with a cut prefix are still told apart
+// from legacy IDs.
+func truncateVectorIDPrefix(prefix string) string {
+	if len(prefix) > maxVectorIDPrefix {
+		prefix = strings.ToValidUTF8(prefix[:maxVectorIDPrefix-1], "") + "#"
+	}
+	return prefix
+}
+
+// connectIndex opens a connection to the configured Pinecone index
+func connectIndex() (*pinecone.IndexConnection, error) {
+	if database.DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	index, err := database.DB.PineconeClient.Index(pinecone.NewIndexConnParams{
+		Host: database.DB.Config.PineconeHost,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to index: %w", err)
+	}
+	return index, nil
+}
+
+// DeleteFileVectors deletes the vectors of the given files of a repository
+// branch. Each file's vector IDs are listed by their prefix, which needs a
+// serverless Pinecone index.
+func DeleteFileVectors(ctx context.Context, repository, branch string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	index, err := connectIndex()
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+
+	var ids []string
+	for _, path := range paths {
+		err := listVectorIDs(ctx, index, fileVectorIDPrefix(repository, branch, path), func(page []string) error {
+			ids = append(ids, page...)
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+	}
+
+	if err := deleteVectorIDs(ctx, index, ids); err != nil {
+		return err
+	}
+
+	log.Printf("🗑️  Deleted %d vectors of %d files from %s@%s", len(ids), len(paths), repository, branch)
+	return nil
+}
+
+// ListRepositoryVectorIDs returns the IDs of the vectors of a repository
+// branch, or of every branch when branch is empty. The IDs are listed by
+// their readable prefix, which needs a serverless Pinecone index.
+func ListRepositoryVectorIDs(ctx context.Context, repository, branch string) ([]string, error) {
+	index, err := connectIndex()
+	if err != nil {
+		return nil, err
+	}
+	defer index.Close()
+
+	prefix, exact := repositoryVectorIDPrefix(repository, branch)
+	var ids []string
+	err = listVectorIDs(ctx, index, prefix, func(page []string) error {
+		ids = append(ids, page...)
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if exact {
+		return ids, nil
+	}
+
+	// A cut prefix may match other repositories or branches, so keep only
+	// the vectors whose metadata names this one
+	var matching []string
+	for start := 0; start < len(ids); start += migrationBatchSize {
+		end := start + migrationBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		fetched, err := index.FetchVectors(ctx, ids[start:end])
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to fetch vectors: %w", err)
+		}
+		for id, vector := range fetched.Vectors {
+			if vector.Metadata == nil {
+				continue
+			}
+			metadata := vector.Metadata.AsMap()
+			if metadataString(metadata, "repository") == repository && (branch == "" || metadataString(metadata, "branch") == branch) {
+				matching = append(matching, id)
+			}
+		}
+	}
+	return matching, nil
+}
+
+// DeleteVectorIDs deletes the vectors with the given IDs
+func DeleteVectorIDs(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	index, err := connectIndex()
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+
+	if err := deleteVectorIDs(ctx, index, ids); err != nil {
+		return err
+	}
+
+	log.Printf("🗑️  Deleted %d vectors", len(ids))
+	return nil
+}
+
+// deleteVectorIDs deletes the vectors with the given IDs in batches
+func deleteVectorIDs(ctx context.Context, index *pinecone.IndexConnection, ids []string) error {
+	for start := 0; start < len(ids); start += deleteIDBatchSize {
+		end := start + deleteIDBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		if err := index.DeleteVectorsById(ctx, ids[start:end]); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to delete vectors: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteBranchVectors deletes every vector of a repository branch. Its
+// vector IDs are listed by prefix, which needs a serverless Pinecone index.
+func DeleteBranchVectors(ctx context.Context, repository, branch string) error {
+	ids, err := ListRepositoryVectorIDs(ctx, repository, branch)
+	if err != nil {
+		return err
+	}
+	if err := DeleteVectorIDs(ctx, ids); err != nil {
+		return err
+	}
+
+	log.Printf("🗑️  Deleted all %d vectors of %s@%s", len(ids), repository, branch)
+	return nil
+}
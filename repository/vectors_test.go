@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestVectorID(t *testing.T) {
+	longRepo := "gitlab.com/" + strings.Repeat("gruppe-ä/", 40) + "project"
+
+	tests := []struct {
+		name       string
+		repository string
+		branch     string
+		filePath   string
+		ordinal    int
+		wantPrefix string
+	}{
+		{name: "github repository", repository: "owner/repo", branch: "main", filePath: "cmd/main.go", wantPrefix: "owner/repo#main#"},
+		{name: "nested repository", repository: "gitlab.com/group/sub/project", branch: "release/1.0", filePath: "a.go", ordinal: 3, wantPrefix: "gitlab.com/group/sub/project#release/1.0#"},
+		{name: "long repository", repository: longRepo, branch: "main", filePath: "a.go", wantPrefix: strings.ToValidUTF8(longRepo[:maxVectorIDPrefix-1], "") + "#"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := VectorID(tt.repository, tt.branch, tt.filePath, tt.ordinal)
+			if !strings.HasPrefix(id, tt.wantPrefix) {
+				t.Errorf("VectorID() = %q, want prefix %q", id, tt.wantPrefix)
+			}
+			if filePrefix := fileVectorIDPrefix(tt.repository, tt.branch, tt.filePath); !strings.HasPrefix(id, filePrefix) {
+				t.Errorf("VectorID() = %q, want file prefix %q", id, filePrefix)
+			}
+			if id != VectorID(tt.repository, tt.branch, tt.filePath, tt.ordinal) {
+				t.Error("VectorID() is not deterministic")
+			}
+			if !vectorIDRegex.MatchString(id) {
+				t.Errorf("VectorID() = %q is not recognised as a new ID", id)
+			}
+			if len(id) > 512 || !utf8.ValidString(id) {
+				t.Errorf("VectorID() = %q is not a valid Pinecone ID", id)
+			}
+		})
+	}
+}
+
+// chunkRef names a chunk of the VectorID tables
+type chunkRef struct {
+	repository, branch, filePath string
+	ordinal                      int
+}
+
+func TestVectorIDDistinguishesChunks(t *testing.T) {
+	// Pairs that collided or were truncated under the legacy
+	// repo-path-with-dashes-i scheme
+	longPath := strings.Repeat("very/long/directory/", 10)
+	pairs := []struct {
+		name string
+		a, b chunkRef
+	}{
+		{name: "dashes versus slashes", a: chunkRef{"owner/repo", "main", "a/b-c.go", 0}, b: chunkRef{"owner/repo", "main", "a-b/c.go", 0}},
+		{name: "long shared prefix", a: chunkRef{"owner/repo", "main", longPath + "a.go", 0}, b: chunkRef{"owner/repo", "main", longPath + "b.go", 0}},
+		{name: "ordinal", a: chunkRef{"owner/repo", "main", "a.go", 1}, b: chunkRef{"owner/repo", "main", "a.go", 11}},
+		{name: "branch", a: chunkRef{"owner/repo", "main", "a.go", 0}, b: chunkRef{"owner/repo", "dev", "a.go", 0}},
+		{name: "repository", a: chunkRef{"owner/repo", "main", "a.go", 0}, b: chunkRef{"owner/repo2", "main", "a.go", 0}},
+	}
+
+	for _, tt := range pairs {
+		t.Run(tt.name, func(t *testing.T) {
+			a := VectorID(tt.a.repository, tt.a.branch, tt.a.filePath, tt.a.ordinal)
+			b := VectorID(tt.b.repository, tt.b.branch, tt.b.filePath, tt.b.ordinal)
+			if a == b {
+				t.Errorf("VectorID() = %q for both chunks", a)
+			}
+
+			// Chunks of different files must not be listed together
+			aPrefix := fileVectorIDPrefix(tt.a.repository, tt.a.branch, tt.a.filePath)
+			bPrefix := fileVectorIDPrefix(tt.b.repository, tt.b.branch, tt.b.filePath)
+			if sameFile := tt.a.repository == tt.b.repository && tt.a.branch == tt.b.branch && tt.a.filePath == tt.b.filePath; sameFile != (aPrefix == bPrefix) {
+				t.Errorf("fileVectorIDPrefix() = %q and %q for chunks of the same file: %v", aPrefix, bPrefix, sameFile)
+			}
+		})
+	}
+}
+
+func TestRepositoryVectorIDPrefix(t *testing.T) {
+	longRepo := strings.Repeat("a", maxVectorIDPrefix) + "/repo"
+
+	tests := []struct {
+		name       string
+		repository string
+		branch     string
+		want       string
+		wantExact  bool
+	}{
+		{name: "branch", repository: "owner/repo", branch: "main", want: "owner/repo#main#", wantExact: true},
+		{name: "every branch", repository: "owner/repo", want: "owner/repo#", wantExact: true},
+		{name: "cut prefix", repository: longRepo, branch: "main", want: longRepo[:maxVectorIDPrefix-1] + "#", wantExact: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, exact := repositoryVectorIDPrefix(tt.repository, tt.branch)
+			if got != tt.want || exact != tt.wantExact {
+				t.Errorf("repositoryVectorIDPrefix() = %q, %v, want %q, %v", got, exact, tt.want, tt.wantExact)
+			}
+			if tt.branch != "" && !strings.HasPrefix(VectorID(tt.repository, tt.branch, "a.go", 0), got) {
+				t.Errorf("prefix %q does not match the branch's vector IDs", got)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"mcp-go-server/database"
 	"mcp-go-server/handlers"
+	"mcp-go-server/mcp"
 	"mcp-go-server/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -24,9 +25,19 @@ func MCPRoutes(rg *gin.RouterGroup, db *database.Database) {
 
 		// Repository indexing endpoints
 		protected.POST("/index", handlers.IndexRepository)
+		protected.POST("/index/upload", handlers.UploadRepository)
+		protected.GET("/index/jobs", handlers.ListIndexJobs)
+		protected.GET("/index/jobs/:id", handlers.GetIndexJob)
 		protected.GET("/repositories", handlers.GetRepositories)
+		protected.DELETE("/repositories/:owner/*name", handlers.DeleteRepository)
 
 		// User management endpoints
 		protected.GET("/profile", handlers.GetProfile)
+
+		// MCP Streamable HTTP transport
+		mcpServer := mcp.NewServer()
+		protected.POST("/mcp", middleware.MCPOriginMiddleware(), handlers.MCPPost(mcpServer))
+		protected.GET("/mcp", middleware.MCPOriginMiddleware(), handlers.MCPGet(mcpServer))
+		protected.DELETE("/mcp", middleware.MCPOriginMiddleware(), handlers.MCPDelete(mcpServer))
 	}
 }
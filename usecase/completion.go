@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"mcp-go-server/domain"
+	"mcp-go-server/helper"
+	"mcp-go-server/repository"
+	"sort"
+	"strings"
+)
+
+// SuggestRepositories returns the indexed repositories the user may read in
+// owner/name format matching the given partial value
+func SuggestRepositories(userID, partial string) []string {
+	var names []string
+	for _, repo := range readableRepositories(userID) {
+		names = append(names, qualifiedName(repo.Owner, repo.Name))
+	}
+	return matchSuggestions(names, partial)
+}
+
+// SuggestOwners returns the owners of readable indexed repositories
+// matching partial
+func SuggestOwners(userID, partial string) []string {
+	var owners []string
+	for _, repo := range readableRepositories(userID) {
+		owners = append(owners, repo.Owner)
+	}
+	return matchSuggestions(owners, partial)
+}
+
+// SuggestRepositoryNames returns readable indexed repository names,
+// optionally restricted to one owner, matching partial
+func SuggestRepositoryNames(userID, owner, partial string) []string {
+	var names []string
+	for _, repo := range readableRepositories(userID) {
+		if owner == "" || repo.Owner == owner {
+			names = append(names, repo.Name)
+		}
+	}
+	return matchSuggestions(names, partial)
+}
+
+// SuggestBranches returns readable indexed branches, optionally restricted
+// to one repository in owner/name format, matching partial
+func SuggestBranches(userID, repositoryName, partial string) []string {
+	var branches []string
+	for _, repo := range readableRepositories(userID) {
+		if repositoryName == "" || qualifiedName(repo.Owner, repo.Name) == repositoryName {
+			branches = append(branches, repo.Branch)
+		}
+	}
+	return matchSuggestions(branches, partial)
+}
+
+// SuggestFilePaths returns readable indexed file paths of a repository
+// branch matching partial
+func SuggestFilePaths(userID, repositoryName, branch, partial string) []string {
+	var paths []string
+	for _, file := range ListIndexedFiles(userID) {
+		if file.Repository == repositoryName && (branch == "" || file.Branch == branch) {
+			paths = append(paths, file.Path)
+		}
+	}
+	return matchSuggestions(paths, partial)
+}
+
+// DefaultBranch returns the branch to use for a repository when a request
+// names none: its most recently indexed branch the user may read, or "main"
+// when the catalog does not know the repository
+func DefaultBranch(userID, repositoryName string) string {
+	repositoryName = helper.NormalizeRepoID(repositoryName)
+	branch, indexedAt := "main", ""
+	for _, repo := range readableRepositories(userID) {
+		if qualifiedName(repo.Owner, repo.Name) == repositoryName && (indexedAt == "" || repo.IndexedAt > indexedAt) {
+			branch, indexedAt = repo.Branch, repo.IndexedAt
+		}
+	}
+	return branch
+}
+
+// readableRepositories returns the indexed repository branches the user
+// may read
+func readableRepositories(userID string) []domain.Repository {
+	var repos []domain.Repository
+	for _, repo := range repository.ListIndexedRepositories() {
+		if canReadRepository(userID, qualifiedName(repo.Owner, repo.Name), repo.Private) {
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
+
+func qualifiedName(owner, name string) string {
+	if owner == "" {
+		return name
+	}
+	return owner + "/" + name
+}
+
+// matchSuggestions deduplicates candidates and returns those matching
+// partial case-insensitively, prefix matches first
+func matchSuggestions(candidates []string, partial string) []string {
+	partial = strings.ToLower(partial)
+	seen := make(map[string]bool)
+	var prefixMatches, otherMatches []string
+
+	for _, candidate := range candidates {
+		if candidate == "" || seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+
+		lower := strings.ToLower(candidate)
+		switch {
+		case strings.HasPrefix(lower, partial):
+			prefixMatches = append(prefixMatches, candidate)
+		case strings.Contains(lower, partial):
+			otherMatches = append(otherMatches, candidate)
+		}
+	}
+
+	sort.Strings(prefixMatches)
+	sort.Strings(otherMatches)
+	return append(prefixMatches, otherMatches...)
+}
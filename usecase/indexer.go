@@ -1,24 +1,138 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"mcp-go-server/chunker"
+	"mcp-go-server/database"
+	"mcp-go-server/domain"
 	"mcp-go-server/helper"
 	"mcp-go-server/models"
 	"mcp-go-server/repository"
 	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
-// IndexRepository indexes a GitHub repository
+// IndexListener is notified when a repository branch finishes indexing or
+// is deleted
+type IndexListener func(repositoryName, branch string)
+
+var (
+	indexListenersMu sync.RWMutex
+	indexListeners   []IndexListener
+)
+
+// AddIndexListener registers a listener for completed indexing runs and
+// deleted repositories
+func AddIndexListener(listener IndexListener) {
+	indexListenersMu.Lock()
+	indexListeners = append(indexListeners, listener)
+	indexListenersMu.Unlock()
+}
+
+// notifyIndexListeners informs listeners that a repository branch was
+// indexed or deleted
+func notifyIndexListeners(repositoryName, branch string) {
+	indexListenersMu.RLock()
+	listeners := append([]IndexListener(nil), indexListeners...)
+	indexListenersMu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(repositoryName, branch)
+	}
+}
+
+// activeIndexing counts the running indexing runs of each repository branch,
+// whichever entry point started them
+var (
+	activeIndexingMu sync.Mutex
+	activeIndexing   = make(map[[2]string]int)
+)
+
+// beginIndexing marks a repository branch as being indexed until the
+// returned function is called
+func beginIndexing(repositoryName, branch string) func() {
+	key := [2]string{repositoryName, branch}
+	activeIndexingMu.Lock()
+	activeIndexing[key]++
+	activeIndexingMu.Unlock()
+
+	return func() {
+		activeIndexingMu.Lock()
+		if activeIndexing[key]--; activeIndexing[key] <= 0 {
+			delete(activeIndexing, key)
+		}
+		activeIndexingMu.Unlock()
+	}
+}
+
+// isIndexing reports whether a repository branch, or any branch of the
+// repository when branch is empty, is queued or being indexed
+func isIndexing(repositoryName, branch string) bool {
+	if repository.HasActiveIndexJob(repositoryName, branch) {
+		return true
+	}
+
+	activeIndexingMu.Lock()
+	defer activeIndexingMu.Unlock()
+	for key := range activeIndexing {
+		if key[0] == repositoryName && (branch == "" || key[1] == branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// IndexRepository indexes a git repository
 func IndexRepository(indexReq models.IndexRequest) (models.IndexResponse, error) {
+	return IndexRepositoryWithProgress(context.Background(), indexReq, nil)
+}
+
+// IndexRepositoryForUser indexes a git repository on behalf of a user.
+// Private repositories are cloned with the user's GitHub token and stay
+// readable by the users who cloned them only. A successful run allows the
+// user to delete the index later.
+func IndexRepositoryForUser(ctx context.Context, userID string, indexReq models.IndexRequest, onProgress repository.ProgressFunc) (models.IndexResponse, error) {
+	result, err := IndexRepositoryWithProgress(WithUserGitToken(ctx, userID), indexReq, onProgress)
+	recordRepositoryOwner(userID, result, err)
+	return result, err
+}
+
+// recordRepositoryOwner lets a user delete a repository they indexed and,
+// for private repositories, read it. Only successful runs count, so users
+// who cannot access a repository never become its owner or reader.
+func recordRepositoryOwner(userID string, result models.IndexResponse, err error) {
+	if err == nil && result.Repository != "" {
+		repository.AddRepositoryOwner(result.Repository, userID)
+		if result.Private {
+			repository.AddRepositoryReader(result.Repository, userID)
+		}
+	}
+}
+
+// canReadRepository reports whether a user may read indexed content of a
+// repository. Private content is only readable by the users who cloned the
+// repository with their own token.
+func canReadRepository(userID, repositoryName string, private bool) bool {
+	return !private || repository.IsRepositoryReader(repositoryName, userID)
+}
+
+// IndexRepositoryWithProgress indexes a git repository, reporting progress
+// to onProgress. Cancelling ctx stops the clone, embedding and upsert work
+// and returns models.ErrIndexingCancelled.
+func IndexRepositoryWithProgress(ctx context.Context, indexReq models.IndexRequest, onProgress repository.ProgressFunc) (models.IndexResponse, error) {
 	startTime := time.Now()
 	log.Printf("🚀 Starting repository indexing for: %s (branch: %s)", indexReq.RepoURL, indexReq.Branch)
 
 	// Validate repository URL
 	log.Printf("📋 Validating repository URL...")
-	if err := helper.ValidateGitHubRepoURL(indexReq.RepoURL); err != nil {
+	if err := helper.ValidateRepoURL(indexReq.RepoURL); err != nil {
 		log.Printf("❌ Repository URL validation failed: %v", err)
 		return models.IndexResponse{}, fmt.Errorf("invalid repository URL: %w", err)
 	}
@@ -32,35 +146,122 @@ func IndexRepository(indexReq models.IndexRequest) (models.IndexResponse, error)
 	}
 	log.Printf("✅ Branch validation passed")
 
-	// Set default branch
+	// Validate chunking options
+	if err := processOptions(indexReq).Chunking.Validate(); err != nil {
+		log.Printf("❌ Chunking options validation failed: %v", err)
+		return models.IndexResponse{}, fmt.Errorf("invalid chunking options: %w", err)
+	}
+
+	// Validate include and exclude globs
+	if err := validateGlobs(indexReq); err != nil {
+		log.Printf("❌ Glob validation failed: %v", err)
+		return models.IndexResponse{}, err
+	}
+
+	// Use the remote's default branch when none is given
 	if indexReq.Branch == "" {
-		indexReq.Branch = "main"
-		log.Printf("📝 Using default branch: main")
+		branch, err := repository.ResolveRef(ctx, indexReq.RepoURL, "")
+		if err != nil {
+			if ctx.Err() != nil {
+				return models.IndexResponse{}, models.ErrIndexingCancelled
+			}
+			log.Printf("❌ Failed to resolve default branch: %v", err)
+			reportIndexStatus(onProgress, indexReq, startTime, "failed", err.Error())
+			return models.IndexResponse{}, fmt.Errorf("failed to resolve default branch: %w", err)
+		}
+		indexReq.Branch = branch
+		log.Printf("📝 Using default branch: %s", branch)
+	}
+
+	// Repositories that only clone with the user's token are indexed as
+	// private, hiding them from users who cannot read them
+	private := repository.HasGitToken(ctx) && !repository.IsPublicRepository(ctx, indexReq.RepoURL)
+	if private {
+		log.Printf("🔒 Repository is private, restricting its index to users with access")
 	}
 
+	// Extract repository name
+	repoName := helper.ExtractRepoName(indexReq.RepoURL)
+	defer beginIndexing(repoName, indexReq.Branch)()
+
 	// Clone repository
 	log.Printf("📥 Cloning repository...")
-	repoPath, err := repository.CloneRepository(indexReq.RepoURL, indexReq.Branch)
+	reportIndexStatus(onProgress, indexReq, startTime, "cloning", "Cloning repository")
+	repoPath, release, err := repository.CloneRepository(ctx, indexReq.RepoURL, indexReq.Branch)
 	if err != nil {
+		if ctx.Err() != nil {
+			log.Printf("🛑 Repository cloning cancelled")
+			reportIndexStatus(onProgress, indexReq, startTime, "cancelled", "Indexing cancelled")
+			return models.IndexResponse{}, models.ErrIndexingCancelled
+		}
 		log.Printf("❌ Repository cloning failed: %v", err)
+		reportIndexStatus(onProgress, indexReq, startTime, "failed", err.Error())
 		return models.IndexResponse{}, fmt.Errorf("failed to clone repository: %w", err)
 	}
-	defer os.RemoveAll(repoPath) // Clean up temp directory
+	defer release() // Clean up the checkout
 	log.Printf("✅ Repository cloned successfully to: %s", repoPath)
 
+	headSHA, err := repository.HeadCommit(ctx, repoPath)
+	if err != nil {
+		log.Printf("❌ Failed to resolve indexed commit: %v", err)
+		reportIndexStatus(onProgress, indexReq, startTime, "failed", err.Error())
+		return models.IndexResponse{}, err
+	}
+
+	// Only re-embed what changed since the last indexed commit when possible
+	changes, incremental, err := planIncrementalIndex(ctx, repoPath, repoName, indexReq, headSHA, private)
+	if err != nil {
+		if ctx.Err() != nil {
+			reportIndexStatus(onProgress, indexReq, startTime, "cancelled", "Indexing cancelled")
+			return models.IndexResponse{}, models.ErrIndexingCancelled
+		}
+		reportIndexStatus(onProgress, indexReq, startTime, "failed", err.Error())
+		return models.IndexResponse{}, err
+	}
+
+	if incremental && len(changes.Changed) == 0 && len(changes.Removed) == 0 {
+		log.Printf("✅ %s@%s is already indexed at %s", repoName, indexReq.Branch, headSHA)
+		reportIndexStatus(onProgress, indexReq, startTime, "completed", fmt.Sprintf("Already up to date at %s", headSHA))
+		return models.IndexResponse{
+			Repository:  repoName,
+			Branch:      indexReq.Branch,
+			Status:      "completed",
+			CommitSHA:   headSHA,
+			Incremental: true,
+			Private:     private,
+		}, nil
+	}
+
 	// Process repository files
 	log.Printf("🔄 Processing repository files and generating embeddings...")
-	fileCount, chunkCount, err := repository.ProcessRepositoryFiles(repoPath, indexReq.RepoURL, indexReq.Branch)
+	opts := processOptions(indexReq)
+	opts.CommitSHA = headSHA
+	opts.Private = private
+
+	var fileCount, chunkCount int
+	var failures []models.BatchFailure
+	if incremental {
+		fileCount, chunkCount, failures, err = repository.ProcessChangedFiles(ctx, repoPath, indexReq.RepoURL, indexReq.Branch, changes, opts, onProgress)
+	} else {
+		fileCount, chunkCount, failures, err = repository.ProcessRepositoryFiles(ctx, repoPath, indexReq.RepoURL, indexReq.Branch, opts, onProgress)
+	}
 	if err != nil {
+		if ctx.Err() != nil {
+			log.Printf("🛑 Repository processing cancelled after %d files", fileCount)
+			reportIndexStatus(onProgress, indexReq, startTime, "cancelled", "Indexing cancelled")
+			return models.IndexResponse{}, models.ErrIndexingCancelled
+		}
 		log.Printf("❌ Repository processing failed: %v", err)
+		reportIndexStatus(onProgress, indexReq, startTime, "failed", err.Error())
 		return models.IndexResponse{}, fmt.Errorf("failed to process repository files: %w", err)
 	}
 
 	// If no files were processed, return a special error
-	if fileCount == 0 {
+	if fileCount == 0 && !incremental {
 		log.Printf("⚠️  No files found to process in repository: %s", indexReq.RepoURL)
+		reportIndexStatus(onProgress, indexReq, startTime, "failed", "No files found to process")
 		return models.IndexResponse{
-			Repository: helper.ExtractRepoName(indexReq.RepoURL),
+			Repository: repoName,
 			Branch:     indexReq.Branch,
 			FileCount:  0,
 			ChunkCount: 0,
@@ -68,8 +269,18 @@ func IndexRepository(indexReq models.IndexRequest) (models.IndexResponse, error)
 		}, errors.New("no files found to process in the repository; it may be empty or unsupported")
 	}
 
-	// Extract repository name
-	repoName := helper.ExtractRepoName(indexReq.RepoURL)
+	failedChunks, err := checkBatchFailures(chunkCount, failures)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		reportIndexStatus(onProgress, indexReq, startTime, "failed", err.Error())
+		return models.IndexResponse{}, err
+	}
+
+	// Later re-indexes diff against this commit. After failed batches the
+	// next run diffs from the previous commit again, retrying their files.
+	if len(failures) == 0 {
+		repository.SetIndexedCommit(repoName, indexReq.Branch, headSHA, indexSettings(indexReq))
+	}
 
 	duration := time.Since(startTime)
 	log.Printf("🎉 Repository indexing completed successfully!")
@@ -78,35 +289,293 @@ func IndexRepository(indexReq models.IndexRequest) (models.IndexResponse, error)
 	log.Printf("   - Branch: %s", indexReq.Branch)
 	log.Printf("   - Files processed: %d", fileCount)
 	log.Printf("   - Chunks created: %d", chunkCount)
+	if incremental {
+		log.Printf("   - Files removed: %d", len(changes.Removed))
+	}
+	log.Printf("   - Chunks failed: %d", failedChunks)
+	log.Printf("   - Commit: %s", headSHA)
 	log.Printf("   - Total time: %v", duration)
 
-	// Save repository info (in a real implementation, this would save to database)
-	// For now, we'll skip this step
+	notifyIndexListeners(repoName, indexReq.Branch)
+
+	if onProgress != nil {
+		onProgress(models.IndexProgress{
+			Repository:   repoName,
+			Branch:       indexReq.Branch,
+			Status:       "completed",
+			CurrentFile:  fileCount,
+			TotalFiles:   fileCount,
+			CurrentChunk: chunkCount,
+			TotalChunks:  chunkCount,
+			Message:      indexSummaryMessage(fileCount, chunkCount, failedChunks),
+			StartTime:    startTime.UTC().Format(time.RFC3339),
+			EndTime:      time.Now().UTC().Format(time.RFC3339),
+		})
+	}
 
 	return models.IndexResponse{
-		Repository: repoName,
-		Branch:     indexReq.Branch,
-		FileCount:  fileCount,
-		ChunkCount: chunkCount,
-		Status:     "completed",
+		Repository:   repoName,
+		Branch:       indexReq.Branch,
+		FileCount:    fileCount,
+		ChunkCount:   chunkCount,
+		Status:       "completed",
+		CommitSHA:    headSHA,
+		Incremental:  incremental,
+		Private:      private,
+		RemovedFiles: len(changes.Removed),
+		FailedChunks: failedChunks,
+		Failures:     failures,
 	}, nil
 }
 
+// checkBatchFailures totals the chunks of failed batches and fails the run
+// when no chunk at all could be stored
+func checkBatchFailures(chunkCount int, failures []models.BatchFailure) (int, error) {
+	failedChunks := 0
+	for _, failure := range failures {
+		failedChunks += failure.Chunks
+	}
+
+	if chunkCount == 0 && len(failures) > 0 {
+		return failedChunks, fmt.Errorf("failed to store any of %d chunks: %s failed: %s", failedChunks, failures[0].Stage, failures[0].Error)
+	}
+	return failedChunks, nil
+}
+
+// planIncrementalIndex decides whether a branch can be re-indexed
+// incrementally and returns the files changed since its last indexed commit.
+// Every full run deletes the vectors of the branch first, including any the
+// catalog does not list. A change of visibility re-indexes every file so all
+// chunks carry the new private flag.
+func planIncrementalIndex(ctx context.Context, repoPath, repoName string, indexReq models.IndexRequest, headSHA string, private bool) (domain.FileChanges, bool, error) {
+	previousSHA, previousSettings, indexed := repository.GetIndexedCommit(repoName, indexReq.Branch)
+	if !indexed {
+		return domain.FileChanges{}, false, deletePreviousIndex(ctx, repoName, indexReq.Branch)
+	}
+
+	visibilityChanged := repository.IsPrivateBranch(repoName, indexReq.Branch) != private
+	if visibilityChanged {
+		log.Printf("⚠️  Visibility of %s changed, falling back to a full re-index", repoName)
+	}
+
+	// Other globs or chunking options select or split files differently,
+	// including files the diff does not touch
+	settingsChanged := previousSettings != indexSettings(indexReq)
+	if settingsChanged {
+		log.Printf("⚠️  Indexing settings of %s changed, falling back to a full re-index", repoName)
+	}
+
+	if !indexReq.Full && !visibilityChanged && !settingsChanged {
+		if previousSHA == headSHA {
+			return domain.FileChanges{}, true, nil
+		}
+
+		// A shallow clone only holds HEAD, so fetch the previous commit too
+		if !repository.CommitExists(ctx, repoPath, previousSHA) {
+			if err := repository.FetchCommit(ctx, repoPath, previousSHA); err != nil {
+				log.Printf("⚠️  %v", err)
+			}
+		}
+
+		if repository.CommitExists(ctx, repoPath, previousSHA) {
+			changes, err := repository.DiffCommits(ctx, repoPath, previousSHA, headSHA)
+			switch {
+			case err == nil && !changesIgnoreRules(changes):
+				log.Printf("🔀 Incremental re-index of %s@%s from %s to %s", repoName, indexReq.Branch, previousSHA, headSHA)
+				return changes, true, nil
+			case err == nil:
+				log.Printf("⚠️  Ignore rules changed since %s, falling back to a full re-index", previousSHA)
+			case ctx.Err() != nil:
+				return domain.FileChanges{}, false, ctx.Err()
+			default:
+				log.Printf("⚠️  %v, falling back to a full re-index", err)
+			}
+		} else {
+			log.Printf("⚠️  Previously indexed commit %s is no longer available, falling back to a full re-index", previousSHA)
+		}
+	}
+
+	return domain.FileChanges{}, false, deletePreviousIndex(ctx, repoName, indexReq.Branch)
+}
+
+// deletePreviousIndex deletes every vector of a repository branch before a
+// full run
+func deletePreviousIndex(ctx context.Context, repoName, branch string) error {
+	if err := repository.DeleteBranchVectors(ctx, repoName, branch); err != nil {
+		return fmt.Errorf("failed to remove previous index: %w", err)
+	}
+	return nil
+}
+
+// indexSettings describes the include and exclude globs and the chunking
+// options of an index request, with defaults filled in so equivalent
+// requests compare equal
+func indexSettings(indexReq models.IndexRequest) string {
+	include := append([]string(nil), indexReq.Include...)
+	exclude := append([]string(nil), indexReq.Exclude...)
+	sort.Strings(include)
+	sort.Strings(exclude)
+	chunking := processOptions(indexReq).Chunking.WithDefaults()
+
+	return fmt.Sprintf("include=%q exclude=%q chunking=%s/%d/%d", include, exclude, chunking.Strategy, chunking.Size, chunking.Overlap)
+}
+
+// changesIgnoreRules reports whether an ignore file changed, which can
+// affect files outside the diff
+func changesIgnoreRules(changes domain.FileChanges) bool {
+	for _, paths := range [][]string{changes.Changed, changes.Removed} {
+		for _, changedPath := range paths {
+			for _, name := range helper.IgnoreFileNames {
+				if path.Base(changedPath) == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// IndexLocalDirectory indexes a directory on the server's filesystem, such as
+// an MCP client's workspace root, under a synthetic repository name on
+// behalf of a user
+func IndexLocalDirectory(ctx context.Context, userID, dirPath string, onProgress repository.ProgressFunc) (models.IndexResponse, error) {
+	result, err := indexDirectory(ctx, dirPath, helper.LocalRepoName(dirPath), models.LocalWorkspaceBranch, onProgress)
+	recordRepositoryOwner(userID, result, err)
+	return result, err
+}
+
+// indexDirectory indexes every file below dirPath as branch of repoName. The
+// directory replaces the branch's previous index, including files it no
+// longer has.
+func indexDirectory(ctx context.Context, dirPath, repoName, branch string, onProgress repository.ProgressFunc) (models.IndexResponse, error) {
+	startTime := time.Now()
+	log.Printf("🚀 Starting directory indexing for: %s (as %s@%s)", dirPath, repoName, branch)
+	defer beginIndexing(repoName, branch)()
+
+	info, err := os.Stat(dirPath)
+	if err != nil {
+		return models.IndexResponse{}, fmt.Errorf("cannot access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return models.IndexResponse{}, fmt.Errorf("%s is not a directory", dirPath)
+	}
+
+	if err := deletePreviousIndex(ctx, repoName, branch); err != nil {
+		if ctx.Err() != nil {
+			return models.IndexResponse{}, models.ErrIndexingCancelled
+		}
+		log.Printf("❌ %v", err)
+		return models.IndexResponse{}, err
+	}
+
+	fileCount, chunkCount, failures, err := repository.ProcessRepositoryFiles(ctx, dirPath, repoName, branch, processOptions(models.IndexRequest{}), onProgress)
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Printf("🛑 Directory indexing cancelled after %d files", fileCount)
+			return models.IndexResponse{}, models.ErrIndexingCancelled
+		}
+		log.Printf("❌ Directory processing failed: %v", err)
+		return models.IndexResponse{}, fmt.Errorf("failed to process directory files: %w", err)
+	}
+
+	if fileCount == 0 {
+		log.Printf("⚠️  No files found to process in directory: %s", dirPath)
+		return models.IndexResponse{
+			Repository: repoName,
+			Branch:     branch,
+			Status:     "empty",
+		}, errors.New("no files found to process in the directory; it may be empty or unsupported")
+	}
+
+	failedChunks, err := checkBatchFailures(chunkCount, failures)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return models.IndexResponse{}, err
+	}
+
+	log.Printf("🎉 Directory %s indexed as %s@%s: %d files, %d chunks in %v", dirPath, repoName, branch, fileCount, chunkCount, time.Since(startTime))
+	notifyIndexListeners(repoName, branch)
+
+	return models.IndexResponse{
+		Repository:   repoName,
+		Branch:       branch,
+		FileCount:    fileCount,
+		ChunkCount:   chunkCount,
+		Status:       "completed",
+		FailedChunks: failedChunks,
+		Failures:     failures,
+	}, nil
+}
+
+// processOptions resolves the file processing options of an index request,
+// falling back to the server's concurrency setting
+func processOptions(indexReq models.IndexRequest) repository.ProcessOptions {
+	opts := repository.ProcessOptions{
+		Concurrency: indexReq.Concurrency,
+		Chunking: chunker.Options{
+			Strategy: indexReq.ChunkStrategy,
+			Size:     indexReq.ChunkSize,
+			Overlap:  indexReq.ChunkOverlap,
+		},
+		Include: indexReq.Include,
+		Exclude: indexReq.Exclude,
+	}
+	if opts.Concurrency <= 0 && database.DB != nil {
+		opts.Concurrency = database.DB.Config.IndexConcurrency
+	}
+	return opts
+}
+
+// validateGlobs checks the include and exclude globs of an index request
+func validateGlobs(indexReq models.IndexRequest) error {
+	if err := helper.ValidateGlobs(indexReq.Include); err != nil {
+		return fmt.Errorf("invalid include glob: %w", err)
+	}
+	if err := helper.ValidateGlobs(indexReq.Exclude); err != nil {
+		return fmt.Errorf("invalid exclude glob: %w", err)
+	}
+	return nil
+}
+
+// indexSummaryMessage describes a finished indexing run
+func indexSummaryMessage(fileCount, chunkCount, failedChunks int) string {
+	if failedChunks > 0 {
+		return fmt.Sprintf("Indexed %d files (%d chunks, %d chunks failed)", fileCount, chunkCount, failedChunks)
+	}
+	return fmt.Sprintf("Indexed %d files (%d chunks)", fileCount, chunkCount)
+}
+
+// reportIndexStatus reports a stage or terminal status without file counts
+func reportIndexStatus(onProgress repository.ProgressFunc, indexReq models.IndexRequest, startTime time.Time, status, message string) {
+	if onProgress == nil {
+		return
+	}
+
+	progress := models.IndexProgress{
+		Repository: helper.ExtractRepoName(indexReq.RepoURL),
+		Branch:     indexReq.Branch,
+		Status:     status,
+		Message:    message,
+		StartTime:  startTime.UTC().Format(time.RFC3339),
+	}
+	if status == "completed" || status == "failed" || status == "cancelled" {
+		progress.EndTime = time.Now().UTC().Format(time.RFC3339)
+	}
+	onProgress(progress)
+}
+
 // GetRepositories retrieves list of indexed repositories for user
 func GetRepositories(userID string) ([]models.RepositoryInfo, error) {
 	if userID == "" {
 		return nil, errors.New("user ID is required")
 	}
 
-	// Get repositories from storage
-	repos, err := repository.GetRepositoryInfo(userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve repositories: %w", err)
-	}
-
-	// Convert domain objects to response models
-	var repoInfos []models.RepositoryInfo
-	for _, repo := range repos {
+	// Convert the user's indexed repository branches to response models
+	repoInfos := []models.RepositoryInfo{}
+	for _, repo := range readableRepositories(userID) {
+		if !isRepositoryOwner(qualifiedName(repo.Owner, repo.Name), userID) {
+			continue
+		}
 		repoInfo := models.RepositoryInfo{
 			Name:       repo.Name,
 			Owner:      repo.Owner,
@@ -115,6 +584,7 @@ func GetRepositories(userID string) ([]models.RepositoryInfo, error) {
 			IndexedAt:  repo.IndexedAt,
 			FileCount:  repo.FileCount,
 			ChunkCount: repo.ChunkCount,
+			CommitSHA:  repo.CommitSHA,
 		}
 		repoInfos = append(repoInfos, repoInfo)
 	}
@@ -122,37 +592,110 @@ func GetRepositories(userID string) ([]models.RepositoryInfo, error) {
 	return repoInfos, nil
 }
 
-// DeleteRepository removes an indexed repository
-func DeleteRepository(userID, repository, branch string) error {
+// DeleteRepository removes a branch of an indexed repository, or every
+// branch when branch is empty: its vectors are listed by ID prefix and
+// deleted, and its catalog entries dropped. Only the repository's owners may
+// delete it.
+func DeleteRepository(ctx context.Context, userID, repositoryName, branch string) (models.DeleteRepositoryResponse, error) {
 	if userID == "" {
-		return errors.New("user ID is required")
+		return models.DeleteRepositoryResponse{}, errors.New("user ID is required")
 	}
 
-	if repository == "" {
-		return errors.New("repository is required")
+	if repositoryName == "" {
+		return models.DeleteRepositoryResponse{}, errors.New("repository is required")
 	}
+	repositoryName = helper.NormalizeRepoID(repositoryName)
 
-	// In a real implementation, this would:
-	// 1. Verify user owns the repository index
-	// 2. Delete vectors from Pinecone
-	// 3. Remove repository info from database
+	// Pinecone is the source of truth, so vectors the catalog lost track of
+	// are deleted too
+	ids, err := repository.ListRepositoryVectorIDs(ctx, repositoryName, branch)
+	if err != nil {
+		log.Printf("❌ Failed to list vectors of %s: %v", repositoryName, err)
+		return models.DeleteRepositoryResponse{}, err
+	}
+	if len(ids) == 0 && !isIndexedBranch(repositoryName, branch) {
+		return models.DeleteRepositoryResponse{}, models.ErrRepositoryNotFound
+	}
+	if !isRepositoryOwner(repositoryName, userID) {
+		return models.DeleteRepositoryResponse{}, models.ErrRepositoryForbidden
+	}
+	if isIndexing(repositoryName, branch) {
+		return models.DeleteRepositoryResponse{}, models.ErrIndexingInProgress
+	}
+
+	if err := repository.DeleteVectorIDs(ctx, ids); err != nil {
+		log.Printf("❌ Failed to delete vectors of %s: %v", repositoryName, err)
+		return models.DeleteRepositoryResponse{}, err
+	}
+
+	branches := repository.RemoveIndexedRepository(repositoryName, branch)
+	if len(branches) == 0 && branch != "" {
+		branches = []string{branch}
+	}
+	log.Printf("🗑️  Deleted %s (%s): %d vectors", repositoryName, strings.Join(branches, ", "), len(ids))
+	for _, deleted := range branches {
+		notifyIndexListeners(repositoryName, deleted)
+	}
+
+	return models.DeleteRepositoryResponse{
+		Repository:     repositoryName,
+		Branches:       branches,
+		VectorsRemoved: len(ids),
+	}, nil
+}
+
+// isRepositoryOwner reports whether a user may delete or replace a
+// repository. Uploads belong to the user named in their upload/<user>/
+// prefix; other repositories to the users who indexed them.
+func isRepositoryOwner(repositoryName, userID string) bool {
+	if owner, ok := helper.UploadOwner(repositoryName); ok {
+		return owner == userID
+	}
+	return repository.IsRepositoryOwner(repositoryName, userID)
+}
 
-	return errors.New("delete repository functionality not implemented yet")
+// isIndexedBranch reports whether the catalog holds a repository branch, or
+// any branch of the repository when branch is empty
+func isIndexedBranch(repositoryName, branch string) bool {
+	for _, repo := range repository.ListIndexedRepositories() {
+		if qualifiedName(repo.Owner, repo.Name) == repositoryName && (branch == "" || repo.Branch == branch) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetIndexingStatus retrieves the status of a repository indexing operation
-func GetIndexingStatus(userID, repository, branch string) (string, error) {
+func GetIndexingStatus(userID, repositoryName, branch string) (string, error) {
 	if userID == "" {
 		return "", errors.New("user ID is required")
 	}
 
-	if repository == "" {
+	if repositoryName == "" {
 		return "", errors.New("repository is required")
 	}
+	repositoryName = helper.NormalizeRepoID(repositoryName)
+
+	// The user's most recent job for the branch carries the live status.
+	// Without a branch, their most recent job for the repository does.
+	for _, job := range repository.ListIndexJobs(userID) {
+		if job.Progress.Repository == repositoryName && (branch == "" || job.Branch == branch) {
+			return job.Progress.Status, nil
+		}
+	}
 
-	// In a real implementation, this would check the indexing status
-	// For now, return a placeholder status
-	return "completed", nil
+	if branch == "" {
+		branch = DefaultBranch(userID, repositoryName)
+	}
+
+	// Branches indexed outside a job (e.g. over MCP) are only known once done
+	for _, repo := range repository.ListIndexedRepositories() {
+		if qualifiedName(repo.Owner, repo.Name) == repositoryName && repo.Branch == branch && canReadRepository(userID, repositoryName, repo.Private) {
+			return "completed", nil
+		}
+	}
+
+	return "", models.ErrRepositoryNotFound
 }
 
 // ReindexRepository re-indexes an existing repository
@@ -161,7 +704,38 @@ func ReindexRepository(userID string, indexReq models.IndexRequest) (models.Inde
 		return models.IndexResponse{}, errors.New("user ID is required")
 	}
 
-	// First, delete existing index (in a real implementation)
-	// Then perform new indexing
-	return IndexRepository(indexReq)
+	// Only files changed since the last indexed commit are re-embedded
+	return IndexRepositoryForUser(context.Background(), userID, indexReq, nil)
+}
+
+// GetIndexedFile retrieves the full content of an indexed file. Files of
+// private repositories the user cannot read are reported as missing.
+func GetIndexedFile(userID, repositoryName, branch, path string) (domain.IndexedFile, error) {
+	if repositoryName == "" || path == "" {
+		return domain.IndexedFile{}, errors.New("repository and path are required")
+	}
+	repositoryName = helper.NormalizeRepoID(repositoryName)
+	if branch == "" {
+		branch = DefaultBranch(userID, repositoryName)
+	}
+
+	file, err := repository.GetIndexedFile(repositoryName, branch, path)
+	if err != nil {
+		return domain.IndexedFile{}, err
+	}
+	if !canReadRepository(userID, file.Repository, file.Private) {
+		return domain.IndexedFile{}, models.ErrFileNotFound
+	}
+	return file, nil
+}
+
+// ListIndexedFiles lists every indexed file the user may read
+func ListIndexedFiles(userID string) []domain.IndexedFile {
+	var files []domain.IndexedFile
+	for _, file := range repository.ListIndexedFiles() {
+		if canReadRepository(userID, file.Repository, file.Private) {
+			files = append(files, file)
+		}
+	}
+	return files
 }
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mcp-go-server/helper"
+	"mcp-go-server/models"
+	"mcp-go-server/repository"
+)
+
+// StartIndexJob validates an indexing request and runs it in the background,
+// returning the queued job immediately
+func StartIndexJob(userID string, indexReq models.IndexRequest) (models.IndexJob, error) {
+	if userID == "" {
+		return models.IndexJob{}, errors.New("user ID is required")
+	}
+
+	// Validate up front so bad requests fail before a job is created
+	if err := helper.ValidateRepoURL(indexReq.RepoURL); err != nil {
+		return models.IndexJob{}, fmt.Errorf("invalid repository URL: %w", err)
+	}
+	if err := helper.ValidateBranch(indexReq.Branch); err != nil {
+		return models.IndexJob{}, fmt.Errorf("invalid branch name: %w", err)
+	}
+	if err := processOptions(indexReq).Chunking.Validate(); err != nil {
+		return models.IndexJob{}, fmt.Errorf("invalid chunking options: %w", err)
+	}
+	if err := validateGlobs(indexReq); err != nil {
+		return models.IndexJob{}, err
+	}
+
+	// Resolve the default branch and fail early on missing refs
+	ctx := WithUserGitToken(context.Background(), userID)
+	branch, err := repository.ResolveRef(ctx, indexReq.RepoURL, indexReq.Branch)
+	if err != nil {
+		return models.IndexJob{}, err
+	}
+	indexReq.Branch = branch
+
+	job, err := repository.CreateIndexJob(userID, indexReq.RepoURL, indexReq.Branch)
+	if err != nil {
+		return models.IndexJob{}, err
+	}
+	log.Printf("🗂️  Indexing job %s queued for %s (branch: %s)", job.ID, indexReq.RepoURL, indexReq.Branch)
+
+	go func() {
+		result, err := IndexRepositoryWithProgress(ctx, indexReq, func(progress models.IndexProgress) {
+			repository.UpdateIndexJobProgress(job.ID, progress)
+		})
+		recordRepositoryOwner(userID, result, err)
+
+		var resultPtr *models.IndexResponse
+		if result.Repository != "" {
+			resultPtr = &result
+		}
+		repository.FinishIndexJob(job.ID, resultPtr, err)
+
+		if err != nil {
+			log.Printf("❌ Indexing job %s failed: %v", job.ID, err)
+			return
+		}
+		log.Printf("🎉 Indexing job %s completed", job.ID)
+	}()
+
+	return job, nil
+}
+
+// WithUserGitToken attaches the user's stored GitHub token to ctx so git can
+// clone the private repositories the user has access to. Users without a
+// stored token clone anonymously.
+func WithUserGitToken(ctx context.Context, userID string) context.Context {
+	user, err := repository.GetUserByID(userID)
+	if err != nil || user.AccessToken == "" {
+		return ctx
+	}
+	return repository.WithGitToken(ctx, user.AccessToken)
+}
+
+// GetIndexJob retrieves one of the user's indexing jobs
+func GetIndexJob(userID, jobID string) (models.IndexJob, error) {
+	if userID == "" {
+		return models.IndexJob{}, errors.New("user ID is required")
+	}
+	return repository.GetIndexJob(userID, jobID)
+}
+
+// ListIndexJobs lists the user's indexing jobs, newest first
+func ListIndexJobs(userID string) ([]models.IndexJob, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+	return repository.ListIndexJobs(userID), nil
+}
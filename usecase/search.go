@@ -1,13 +1,24 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"mcp-go-server/database"
+	"mcp-go-server/domain"
+	"mcp-go-server/helper"
 	"mcp-go-server/models"
 	"mcp-go-server/repository"
 )
 
-// PerformVectorSearch executes vector search on repository code
-func PerformVectorSearch(searchReq models.SearchRequest) (models.SearchResponse, error) {
+// PerformVectorSearch executes vector search on repository code. Private
+// repositories the user cannot read are reported as missing.
+func PerformVectorSearch(userID string, searchReq models.SearchRequest) (models.SearchResponse, error) {
+	searchReq.Repository = helper.NormalizeRepoID(searchReq.Repository)
+	if repository.IsPrivateBranch(searchReq.Repository, searchReq.Branch) && !canReadRepository(userID, searchReq.Repository, true) {
+		return models.SearchResponse{}, models.ErrRepositoryNotFound
+	}
+
 	// Validate repository exists
 	exists, err := repository.CheckRepositoryExists(searchReq.Repository, searchReq.Branch)
 	if err != nil {
@@ -29,37 +40,91 @@ func PerformVectorSearch(searchReq models.SearchRequest) (models.SearchResponse,
 		return models.SearchResponse{}, errors.New("vector search failed")
 	}
 
-	// Convert to response format
+	// Chunks carry their visibility, so private results stay hidden even
+	// when the catalog lost track of the repository's readers. A repository
+	// whose every result is hidden is reported as missing.
+	searchResults := readableResults(userID, results)
+	if len(results) > 0 && len(searchResults) == 0 {
+		return models.SearchResponse{}, models.ErrRepositoryNotFound
+	}
+
+	return models.SearchResponse{
+		Results: searchResults,
+		Total:   len(searchResults),
+	}, nil
+}
+
+// readableResults converts the search results the user may read to
+// response format, dropping private results of repositories they did not
+// clone themselves
+func readableResults(userID string, results []domain.SearchResult) []models.SearchResult {
 	var searchResults []models.SearchResult
 	for _, result := range results {
+		if !canReadRepository(userID, result.Repository, result.Private) {
+			continue
+		}
 		searchResults = append(searchResults, models.SearchResult{
 			Content:    result.Content,
 			FilePath:   result.FilePath,
 			Repository: result.Repository,
 			Branch:     result.Branch,
 			Language:   result.Language,
+			StartLine:  result.StartLine,
+			EndLine:    result.EndLine,
+			CommitSHA:  result.CommitSHA,
+			Permalink:  helper.RepoPermalink(result.Repository, result.CommitSHA, result.FilePath, result.StartLine, result.EndLine),
 			Score:      result.Score,
 		})
 	}
-
-	return models.SearchResponse{
-		Results: searchResults,
-		Total:   len(searchResults),
-	}, nil
+	return searchResults
 }
 
+// Summarizer completes a summary prompt with a model chosen by the caller
+type Summarizer func(ctx context.Context, prompt models.SummaryPrompt) (string, error)
+
 // PerformSearchWithSummary executes search and generates AI summary
-func PerformSearchWithSummary(searchReq models.SearchRequest) (models.SearchWithSummaryResponse, error) {
+func PerformSearchWithSummary(userID string, searchReq models.SearchRequest) (models.SearchWithSummaryResponse, error) {
+	return PerformSearchWithClientSummary(context.Background(), userID, searchReq, nil)
+}
+
+// PerformSearchWithClientSummary executes search and generates a summary. In
+// client summary mode the prompt is completed by clientSummarizer instead of
+// OpenAI, so no code is sent to OpenAI from the server. Requests may opt into
+// client summaries but never override a server configured for them.
+func PerformSearchWithClientSummary(ctx context.Context, userID string, searchReq models.SearchRequest, clientSummarizer Summarizer) (models.SearchWithSummaryResponse, error) {
+	summaryMode := "server"
+	if database.DB != nil && database.DB.Config != nil {
+		summaryMode = database.DB.Config.SummaryMode
+	}
+	if searchReq.SummaryMode == "client" {
+		summaryMode = "client"
+	}
+	if summaryMode == "client" && clientSummarizer == nil {
+		return models.SearchWithSummaryResponse{}, models.ErrSummaryModeInvalid
+	}
+
 	// First perform regular search
-	searchResponse, err := PerformVectorSearch(searchReq)
+	searchResponse, err := PerformVectorSearch(userID, searchReq)
 	if err != nil {
 		return models.SearchWithSummaryResponse{}, err
 	}
 
 	// Generate AI summary
-	summary, err := repository.GenerateAISummary(searchResponse.Results, searchReq.Query)
-	if err != nil {
-		return models.SearchWithSummaryResponse{}, errors.New("failed to generate summary")
+	var summary string
+	if summaryMode == "client" {
+		summary = "No results found for the query."
+		if len(searchResponse.Results) > 0 {
+			prompt := repository.BuildSummaryPrompt(searchResponse.Results, searchReq.Query)
+			summary, err = clientSummarizer(ctx, prompt)
+			if err != nil {
+				return models.SearchWithSummaryResponse{}, fmt.Errorf("failed to generate summary: %w", err)
+			}
+		}
+	} else {
+		summary, err = repository.GenerateAISummary(searchResponse.Results, searchReq.Query)
+		if err != nil {
+			return models.SearchWithSummaryResponse{}, errors.New("failed to generate summary")
+		}
 	}
 
 	return models.SearchWithSummaryResponse{
@@ -68,3 +133,14 @@ func PerformSearchWithSummary(searchReq models.SearchRequest) (models.SearchWith
 		Total:   searchResponse.Total,
 	}, nil
 }
+
+// RetrieveSearchContext runs a vector search and formats the matching chunks
+// as prompt context of at most roughly maxLen characters
+func RetrieveSearchContext(userID string, searchReq models.SearchRequest, maxLen int) (string, models.SearchResponse, error) {
+	searchResponse, err := PerformVectorSearch(userID, searchReq)
+	if err != nil {
+		return "", models.SearchResponse{}, err
+	}
+
+	return repository.BuildResultsContext(searchResponse.Results, maxLen), searchResponse, nil
+}
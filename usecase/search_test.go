@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"mcp-go-server/domain"
+	"mcp-go-server/repository"
+	"testing"
+)
+
+func TestReadableResults(t *testing.T) {
+	// Only alice cloned owner/shared; nobody is recorded for owner/lost, as
+	// after losing the catalog
+	repository.AddRepositoryReader("owner/shared", "alice")
+
+	results := []domain.SearchResult{
+		{CodeChunk: domain.CodeChunk{Repository: "owner/public", FilePath: "a.go"}},
+		{CodeChunk: domain.CodeChunk{Repository: "owner/shared", FilePath: "b.go", Private: true}},
+		{CodeChunk: domain.CodeChunk{Repository: "owner/lost", FilePath: "c.go", Private: true}},
+	}
+
+	tests := []struct {
+		name   string
+		userID string
+		want   []string
+	}{
+		{name: "reader", userID: "alice", want: []string{"a.go", "b.go"}},
+		{name: "other user", userID: "bob", want: []string{"a.go"}},
+		{name: "anonymous", userID: "", want: []string{"a.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readableResults(tt.userID, results)
+			var paths []string
+			for _, result := range got {
+				paths = append(paths, result.FilePath)
+			}
+			if len(paths) != len(tt.want) {
+				t.Fatalf("readableResults() = %v, want %v", paths, tt.want)
+			}
+			for i := range paths {
+				if paths[i] != tt.want[i] {
+					t.Errorf("readableResults() = %v, want %v", paths, tt.want)
+				}
+			}
+		})
+	}
+}
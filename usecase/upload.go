@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mcp-go-server/helper"
+	"mcp-go-server/models"
+	"mcp-go-server/repository"
+)
+
+// StartUploadIndexJob extracts an uploaded .tar.gz or .zip archive and
+// indexes it in the background as ref of the user's upload/<user>/<name>
+// repository, returning the queued job immediately. Unsafe or malformed
+// archives are rejected with models.ErrInvalidArchive before a job is
+// created, and branches indexed by another user with
+// models.ErrRepositoryForbidden.
+func StartUploadIndexJob(userID string, uploadReq models.UploadIndexRequest, archive io.ReaderAt, size int64, filename string) (models.IndexJob, error) {
+	if userID == "" {
+		return models.IndexJob{}, errors.New("user ID is required")
+	}
+	repoName, err := helper.UploadRepoName(userID, uploadReq.Repository)
+	if err != nil {
+		return models.IndexJob{}, fmt.Errorf("invalid repository name: %w", err)
+	}
+	if err := helper.ValidateBranch(uploadReq.Ref); err != nil {
+		return models.IndexJob{}, fmt.Errorf("invalid ref label: %w", err)
+	}
+
+	// Only the user who uploaded a snapshot may replace it
+	if isIndexedBranch(repoName, uploadReq.Ref) && !isRepositoryOwner(repoName, userID) {
+		return models.IndexJob{}, models.ErrRepositoryForbidden
+	}
+
+	format, err := repository.ArchiveFormat(filename)
+	if err != nil {
+		return models.IndexJob{}, err
+	}
+	repoPath, cleanup, err := repository.ExtractArchive(archive, size, format)
+	if err != nil {
+		log.Printf("❌ Rejected uploaded archive %s: %v", filename, err)
+		return models.IndexJob{}, err
+	}
+
+	job, err := repository.CreateIndexJob(userID, repoName, uploadReq.Ref)
+	if err != nil {
+		cleanup()
+		return models.IndexJob{}, err
+	}
+	log.Printf("🗂️  Indexing job %s queued for uploaded archive %s (%s@%s)", job.ID, filename, repoName, uploadReq.Ref)
+
+	go func() {
+		defer cleanup()
+		ctx := context.Background()
+
+		result, err := indexDirectory(ctx, repoPath, repoName, uploadReq.Ref, func(progress models.IndexProgress) {
+			repository.UpdateIndexJobProgress(job.ID, progress)
+		})
+		recordRepositoryOwner(userID, result, err)
+
+		var resultPtr *models.IndexResponse
+		if result.Repository != "" {
+			resultPtr = &result
+		}
+		repository.FinishIndexJob(job.ID, resultPtr, err)
+
+		if err != nil {
+			log.Printf("❌ Indexing job %s failed: %v", job.ID, err)
+			return
+		}
+		log.Printf("🎉 Indexing job %s completed", job.ID)
+	}()
+
+	return job, nil
+}